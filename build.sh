@@ -4,4 +4,7 @@ set -euo pipefail
 # build
 mkdir -p bin
 
-go build -o bin/cyberTools ./cmd/app
+# version injectée depuis le dernier tag git (dev à défaut)
+VERSION=$(git describe --tags --always 2>/dev/null || echo dev)
+
+go build -ldflags "-X main.version=${VERSION}" -o bin/cyberTools ./cmd/app
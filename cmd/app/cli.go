@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Quirky1869/cyberTools/tools"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Version injectée à la compilation par build.sh (-ldflags "-X main.version=...")
+var version = "dev"
+
+// Interprète les arguments de la ligne de commande
+// Renvoie l'outil à ouvrir directement (nil pour démarrer sur le menu), ou done=true avec le code de sortie
+func parseArgs(args []string) (start tea.Model, code int, done bool) {
+	if len(args) == 0 {
+		return nil, 0, false
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		printUsage(os.Stdout)
+		return nil, 0, true
+	case "-v", "--version", "version":
+		fmt.Printf("cyberTools %s\n", version)
+		return nil, 0, true
+	}
+
+	tool, ok := tools.Find(args[0])
+	if !ok || tool.Open == nil {
+		fmt.Fprintf(os.Stderr, "Commande inconnue: %s\n\n", args[0])
+		printUsage(os.Stderr)
+		return nil, 2, true
+	}
+
+	// Mode non interactif (rapport) : aucune TUI n'est lancée
+	if len(args) > 1 && args[1] == "--report" && tool.Headless != nil {
+		return nil, tool.Headless(args[2:]), true
+	}
+
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "Usage: cyberTools %s %s\n", tool.Command, tool.Usage)
+		return nil, 2, true
+	}
+
+	// Les dimensions réelles arrivent avec le premier tea.WindowSizeMsg
+	model, err := tool.Open(0, 0, args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", tool.Name, err)
+		return nil, 1, true
+	}
+
+	return model, 0, false
+}
+
+// Affiche l'aide de la ligne de commande à partir des outils enregistrés
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "  cyberTools\tOuvre le menu principal")
+	for _, t := range tools.Commands() {
+		fmt.Fprintf(tw, "  cyberTools %s %s\tOuvre %s (%s)\n", t.Command, t.Usage, t.Name, t.Description)
+		if t.Headless != nil {
+			fmt.Fprintf(tw, "  cyberTools %s --report %s\tRapport non interactif de %s (--report -h pour les options)\n", t.Command, t.HeadlessUsage, t.Name)
+		}
+	}
+	fmt.Fprintln(tw, "  cyberTools --help\tAffiche cette aide")
+	fmt.Fprintln(tw, "  cyberTools --version\tAffiche la version")
+	tw.Flush()
+}
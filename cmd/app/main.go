@@ -4,13 +4,42 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/Quirky1869/cyberTools/tools/plugin"
+	"github.com/Quirky1869/cyberTools/tools/script"
 	"github.com/Quirky1869/cyberTools/ui"
+
+	// Les outils s'enregistrent eux-mêmes dans le registre lors de leur import
+	// (dans l'ordre de leur chemin d'import, quel que soit l'ordre de cette liste)
+	_ "github.com/Quirky1869/cyberTools/tools/aed"
+	_ "github.com/Quirky1869/cyberTools/tools/logv"
+	_ "github.com/Quirky1869/cyberTools/tools/sqltui"
+	_ "github.com/Quirky1869/cyberTools/tools/structViewer"
+
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	// Chargement des scripts externes déclarés dans le fichier de configuration
+	if err := script.RegisterFromConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration des scripts ignorée: %v\n", err)
+	}
+
+	// Découverte des plugins externes (handshake JSON sur stdout)
+	for _, err := range plugin.RegisterFromDir() {
+		fmt.Fprintf(os.Stderr, "Plugin ignoré: %v\n", err)
+	}
+
+	// Sous-commandes : ouverture directe d'un outil, aide et version
+	start, code, done := parseArgs(os.Args[1:])
+	if done {
+		os.Exit(code)
+	}
+
 	// Initialisation du modèle principal qui contient l'état de l'interface
 	m := ui.NewModel()
+	if start != nil {
+		m = ui.NewModelWithTool(start)
+	}
 
 	// Configuration du programme Bubble Tea avec support de la souris et mode plein écran (AltScreen)
 	p := tea.NewProgram(
@@ -0,0 +1,227 @@
+package aeddsa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Nombre de fichiers et octets cumulés (tranche d'âge, filtre d'âge, propriétaire...)
+type usageStat struct {
+	count    int64
+	size     int64
+	apparent int64
+}
+
+func (s *usageStat) add(n *FileNode) {
+	s.count++
+	s.size += n.Size
+	s.apparent += n.Apparent
+}
+
+// Octets selon le mode de taille choisi
+func (s usageStat) bytes(apparent bool) int64 {
+	if apparent {
+		return s.apparent
+	}
+	return s.size
+}
+
+// Indice de la tranche d'âge d'une date
+func ageBucket(t, now time.Time) int {
+	age := now.Sub(t)
+	for i, b := range ageBuckets {
+		if age < b.max {
+			return i
+		}
+	}
+	return len(ageBuckets) - 1
+}
+
+// Histogramme des fichiers du sous-arbre selon une de leurs dates
+func ageHistogram(files []*FileNode, date func(*FileNode) time.Time, now time.Time) []usageStat {
+	stats := make([]usageStat, len(ageBuckets))
+	for _, f := range files {
+		stats[ageBucket(date(f), now)].add(f)
+	}
+	return stats
+}
+
+// Fichiers modifiés il y a plus de N jours, cumulés sur chaque dossier parent (clé : chemin)
+// Un inode n'est compté qu'une fois dans les dossiers, mais chacun de ses liens reste visible
+func olderThanStats(root *FileNode, days int, now time.Time) map[string]usageStat {
+	cutoff := now.AddDate(0, 0, -days)
+	stats := make(map[string]usageStat)
+	seen := make(map[fileID]struct{})
+
+	var walk func(n *FileNode)
+	walk = func(n *FileNode) {
+		for _, child := range n.Children {
+			if child.IsDir {
+				walk(child)
+				continue
+			}
+			if !child.lastModified().Before(cutoff) {
+				continue
+			}
+			s := stats[child.Path]
+			s.add(child)
+			stats[child.Path] = s
+
+			if child.Ino != 0 {
+				id := fileID{dev: child.Dev, ino: child.Ino}
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+			}
+			for p := child.Parent; p != nil; p = p.Parent {
+				s := stats[p.Path]
+				s.add(child)
+				stats[p.Path] = s
+			}
+		}
+	}
+	walk(root)
+	return stats
+}
+
+// Active (days > 0) ou retire le filtre d'âge, puis retrie l'arbre sur les tailles filtrées
+func (m Model) setAgeFilter(days int) Model {
+	m.olderThan = days
+	m.oldStats = nil
+	if days > 0 {
+		m.oldStats = olderThanStats(m.root, days, time.Now())
+	}
+	m.cursor, m.yOffset = 0, 0
+	return m.resort()
+}
+
+// Recalcule le filtre et les histogrammes ouverts après une modification de l'arbre (suppression, liens physiques)
+func (m Model) refreshAgeFilter() Model {
+	if m.olderThan > 0 {
+		m.oldStats = olderThanStats(m.root, m.olderThan, time.Now())
+	}
+	if m.ageRoot != nil {
+		m = m.computeAge(m.ageRoot)
+	}
+	return m
+}
+
+// Dates comparées dans la vue d'âge
+var ageSections = []struct {
+	label string
+	date  func(*FileNode) time.Time
+}{
+	{"Modifiés", (*FileNode).lastModified},
+	{"Accédés", (*FileNode).lastAccessed},
+}
+
+// Ouvre la vue d'âge sur le dossier courant
+func (m Model) openAge() Model {
+	m.state = StateAge
+	return m.computeAge(m.currentNode)
+}
+
+// Parcourt une seule fois le sous-arbre pour les histogrammes affichés
+func (m Model) computeAge(root *FileNode) Model {
+	files := subtreeFiles(root)
+	now := time.Now()
+	m.ageRoot, m.ageFiles = root, len(files)
+	m.ageStats = make([][]usageStat, len(ageSections))
+	for i, sec := range ageSections {
+		m.ageStats[i] = ageHistogram(files, sec.date, now)
+	}
+	return m
+}
+
+// Ferme la vue d'âge et libère les histogrammes
+func (m Model) closeAge() Model {
+	m.state = StateBrowsing
+	m.ageRoot, m.ageStats = nil, nil
+	return m
+}
+
+// Élément retenu par le filtre d'âge (toujours vrai sans filtre et pour une entrée d'archive)
+func (m Model) passesAgeFilter(n *FileNode) bool {
+	if m.oldStats == nil || n.Virtual {
+		return true
+	}
+	_, ok := m.oldStats[n.Path]
+	return ok
+}
+
+// Saisie du nombre de jours, 0 pour retirer le filtre
+func (m Model) openAgeFilterPrompt() (Model, tea.Cmd) {
+	value := "365"
+	if m.olderThan > 0 {
+		value = strconv.Itoa(m.olderThan)
+	}
+	return m.openPrompt("Filtrer par âge", "Fichiers modifiés il y a plus de (jours, 0 pour tout afficher)", value,
+		func(m Model, input string) (Model, tea.Cmd) {
+			days, err := strconv.Atoi(input)
+			if err != nil || days < 0 {
+				m.status = errorStyle.Render("Nombre de jours invalide : " + input)
+				return m, nil
+			}
+			return m.setAgeFilter(days), nil
+		})
+}
+
+// Libellé du filtre affiché dans l'en-tête
+func (m Model) ageFilterLabel() string {
+	if m.olderThan == 0 {
+		return ""
+	}
+	return fmt.Sprintf("modifiés il y a plus de %d jours", m.olderThan)
+}
+
+// Vue de l'histogramme d'âge du dossier courant, par date de modification et de dernier accès
+func (m Model) viewAge() string {
+	title := titleStyle.Render("AED - Âge des fichiers")
+	header := fmt.Sprintf("  %s  %s  (%s)\n", title, pathStyle.Render(m.ageRoot.Path),
+		infoStyle.Render(fmt.Sprintf("%d fichiers, %s", m.ageFiles, formatBytes(nodeSize(m.ageRoot, m.apparent)))))
+
+	var blocks []string
+	for k, sec := range ageSections {
+		stats := m.ageStats[k]
+		var total int64
+		for _, s := range stats {
+			total += s.bytes(m.apparent)
+		}
+
+		lines := []string{"  " + infoStyle.Render(sec.label)}
+		barWidth := 30
+		for i, s := range stats {
+			size := s.bytes(m.apparent)
+			percent := 0.0
+			if total > 0 {
+				percent = float64(size) / float64(total)
+			}
+			filled := int(percent * float64(barWidth))
+			bar := lipgloss.NewStyle().Foreground(ageBuckets[i].color).Render(strings.Repeat("■", filled)) +
+				barEmpty.Render(strings.Repeat("-", barWidth-filled))
+			lines = append(lines, fmt.Sprintf("    %-12s %s  %10s  %5.1f%%  %s", ageBuckets[i].label, bar,
+				formatBytes(size), percent*100, dimStyle.Render(fmt.Sprintf("%d fichier(s)", s.count))))
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+
+	note := dimStyle.Render("  La date d'accès peut être imprécise sur les systèmes montés en relatime ou noatime.")
+	footer := helpStyle.Render("\nf: filtrer par âge • esc: retour")
+	return fmt.Sprintf("\n%s\n%s\n\n%s\n%s", header, strings.Join(blocks, "\n\n"), note, footer)
+}
+
+func (m Model) updateAge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "A":
+		return m.closeAge(), nil
+	case "f":
+		return m.closeAge().openAgeFilterPrompt()
+	}
+	return m, nil
+}
@@ -0,0 +1,65 @@
+package aeddsa
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Écart minimal entre taille apparente et taille disque pour signaler un fichier creux
+const sparseMinGap = 1 << 20
+
+var sparseStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB800"))
+
+// Taille d'un nœud selon le mode choisi : apparente (transfert) ou occupée sur le disque
+func nodeSize(n *FileNode, apparent bool) int64 {
+	if apparent {
+		return n.Apparent
+	}
+	return n.Size
+}
+
+// Avec un filtre d'âge actif, seuls les fichiers retenus comptent (hors entrées d'archive)
+func (m Model) sizeOf(n *FileNode) int64 {
+	if m.oldStats != nil && !n.Virtual {
+		return m.oldStats[n.Path].bytes(m.apparent)
+	}
+	return nodeSize(n, m.apparent)
+}
+
+// Fichier creux (ou compressé par le système de fichiers) : il occupe bien moins de place que sa taille
+func isSparse(n *FileNode) bool {
+	return !n.IsDir && n.Apparent-n.Size >= sparseMinGap && n.Apparent > 2*n.Size
+}
+
+// Mention ajoutée au nom d'un fichier creux, avec la taille de l'autre mode
+func (m Model) sparseTag(n *FileNode) string {
+	if !isSparse(n) {
+		return ""
+	}
+	if m.apparent {
+		return sparseStyle.Render(fmt.Sprintf(" (creux : %s sur disque)", formatBytes(n.Size)))
+	}
+	return sparseStyle.Render(fmt.Sprintf(" (creux : %s apparents)", formatBytes(n.Apparent)))
+}
+
+// Libellé du mode de taille affiché dans les en-têtes
+func (m Model) sizeLabel() string {
+	if m.currentNode != nil && (m.currentNode.Virtual || !m.currentNode.IsDir) {
+		if m.apparent {
+			return "décompressé"
+		}
+		return "compressé"
+	}
+	if m.apparent {
+		return "apparent"
+	}
+	return "disque"
+}
+
+// Bascule entre taille apparente et taille disque, puis retrie l'arbre
+func (m Model) toggleApparent() Model {
+	m.apparent = !m.apparent
+	m.status = "Tailles affichées : " + m.sizeLabel()
+	return m.resort()
+}
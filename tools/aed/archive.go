@@ -0,0 +1,346 @@
+package aeddsa
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Formats d'archives parcourus sans extraction
+const (
+	archiveZip    = "zip"
+	archiveTar    = "tar"
+	archiveTarGz  = "tar.gz"
+	archiveTarBz2 = "tar.bz2"
+)
+
+// Format d'une archive d'après son nom, vide si le fichier n'en est pas une lisible
+func archiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return archiveTarGz
+	case strings.HasSuffix(lower, ".tar.bz2"), strings.HasSuffix(lower, ".tbz2"):
+		return archiveTarBz2
+	case strings.HasSuffix(lower, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(lower, ".zip"), strings.HasSuffix(lower, ".jar"):
+		return archiveZip
+	}
+	return ""
+}
+
+// Archive réelle qui contient une entrée virtuelle
+func archiveOf(n *FileNode) *FileNode {
+	for n.Virtual && n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
+
+// Chemin sur le disque d'un élément : l'archive elle-même pour une entrée virtuelle
+func diskPath(n *FileNode) string {
+	return archiveOf(n).Path
+}
+
+// Message envoyé lorsque le contenu d'une archive a été lu
+type archiveLoadedMsg struct {
+	archive *FileNode
+	entries []*FileNode
+	err     error
+}
+
+// Construit l'arborescence virtuelle à partir des chemins des entrées
+type archiveBuilder struct {
+	archive *FileNode
+	nodes   map[string]*FileNode // chemin relatif -> nœud
+	entries []*FileNode          // enfants directs de l'archive
+}
+
+// Dossier virtuel, créé avec ses parents s'il n'existe pas encore
+func (b *archiveBuilder) dir(rel string) *FileNode {
+	if rel == "." || rel == "" {
+		return b.archive
+	}
+	if n, ok := b.nodes[rel]; ok && n.IsDir {
+		return n
+	}
+	parent := b.dir(path.Dir(rel))
+	n := &FileNode{Name: path.Base(rel), Path: b.archive.Path + "/" + rel, IsDir: true, Virtual: true, Parent: parent}
+	b.attach(parent, n)
+	b.nodes[rel] = n
+	return n
+}
+
+func (b *archiveBuilder) attach(parent, n *FileNode) {
+	if parent == b.archive {
+		b.entries = append(b.entries, n)
+	} else {
+		parent.Children = append(parent.Children, n)
+	}
+}
+
+// Ajoute une entrée ; un fichier présent plusieurs fois (tar concaténé) garde sa dernière version
+func (b *archiveBuilder) add(name string, isDir bool, size, compressed int64, modTime time.Time, uid, gid uint32) {
+	rel := strings.Trim(path.Clean("/"+name), "/")
+	if rel == "" {
+		return
+	}
+	if isDir {
+		n := b.dir(rel)
+		n.ModTime, n.Uid, n.Gid = modTime, uid, gid
+		return
+	}
+	if n, ok := b.nodes[rel]; ok && !n.IsDir {
+		n.Size, n.Apparent, n.ModTime = compressed, size, modTime
+		return
+	}
+	parent := b.dir(path.Dir(rel))
+	n := &FileNode{Name: path.Base(rel), Path: b.archive.Path + "/" + rel, Size: compressed, Apparent: size,
+		ModTime: modTime, Uid: uid, Gid: gid, Virtual: true, Parent: parent}
+	b.attach(parent, n)
+	b.nodes[rel] = n
+}
+
+// Cumule les tailles des dossiers virtuels et les trie comme les dossiers scannés
+func finalizeArchiveDir(n *FileNode) {
+	var size, apparent int64
+	for _, child := range n.Children {
+		if child.IsDir {
+			finalizeArchiveDir(child)
+		}
+		size += child.Size
+		apparent += child.Apparent
+	}
+	n.Size, n.Apparent = size, apparent
+	finalizeDir(n)
+}
+
+// Lecteur qui compte les octets lus pour la progression
+type countingReader struct {
+	r    io.Reader
+	read *atomic.Int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+// Variante pour un tar non compressé : les sauts par-dessus le contenu des fichiers comptent comme lus
+type countingSeeker struct {
+	countingReader
+	s io.Seeker
+}
+
+func (c countingSeeker) Seek(offset int64, whence int) (int64, error) {
+	pos, err := c.s.Seek(offset, whence)
+	if err == nil {
+		c.read.Store(pos)
+	}
+	return pos, err
+}
+
+// Commande Tea de lecture d'une archive en arrière-plan
+func loadArchiveCmd(ctx context.Context, archive *FileNode, read *atomic.Int64) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := readArchive(ctx, archive, read)
+		return archiveLoadedMsg{archive: archive, entries: entries, err: err}
+	}
+}
+
+// Lit la liste des entrées d'une archive : taille décompressée (apparente) et compressée (disque)
+// Dans un tar compressé, la taille compressée de chaque entrée est estimée au prorata
+func readArchive(ctx context.Context, archive *FileNode, read *atomic.Int64) ([]*FileNode, error) {
+	b := &archiveBuilder{archive: archive, nodes: make(map[string]*FileNode)}
+	format := archiveFormat(archive.Name)
+
+	if format == archiveZip {
+		r, err := zip.OpenReader(archive.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			b.add(f.Name, f.FileInfo().IsDir(), int64(f.UncompressedSize64), int64(f.CompressedSize64), f.Modified, 0, 0)
+		}
+	} else {
+		f, err := os.Open(archive.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		// Un tar non compressé est parcouru par sauts (io.Seeker), sans lire le contenu des fichiers
+		var r io.Reader = countingSeeker{countingReader{f, read}, f}
+		switch format {
+		case archiveTarGz:
+			gz, err := gzip.NewReader(countingReader{f, read})
+			if err != nil {
+				return nil, err
+			}
+			defer gz.Close()
+			r = gz
+		case archiveTarBz2:
+			r = bzip2.NewReader(countingReader{f, read})
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s : %w", archive.Name, err)
+			}
+			size := max(hdr.Size, 0)
+			if hdr.Typeflag != tar.TypeReg {
+				size = 0
+			}
+			// En-tête et données arrondies au bloc de 512 octets
+			blocks := 512 + (size+511)/512*512
+			b.add(hdr.Name, hdr.Typeflag == tar.TypeDir, size, blocks, hdr.ModTime, uint32(hdr.Uid), uint32(hdr.Gid))
+		}
+
+		if format != archiveTar {
+			var total int64
+			for _, n := range b.nodes {
+				if !n.IsDir {
+					total += n.Apparent
+				}
+			}
+			for _, n := range b.nodes {
+				if !n.IsDir && total > 0 {
+					n.Size = int64(float64(n.Apparent) / float64(total) * float64(archive.Apparent))
+				}
+			}
+		}
+	}
+
+	for _, n := range b.entries {
+		if n.IsDir {
+			finalizeArchiveDir(n)
+		}
+	}
+	return b.entries, nil
+}
+
+// Taille totale du dossier affiché ; pour une archive, somme de ses entrées
+func (m Model) currentTotal() int64 {
+	if m.currentNode.IsDir {
+		return m.sizeOf(m.currentNode)
+	}
+	size, apparent := archiveTotals(m.currentNode)
+	if m.apparent {
+		return apparent
+	}
+	return size
+}
+
+// Tailles cumulées des entrées d'une archive ouverte (disque : compressée, apparente : décompressée)
+func archiveTotals(archive *FileNode) (int64, int64) {
+	var size, apparent int64
+	for _, child := range archive.Children {
+		size += child.Size
+		apparent += child.Apparent
+	}
+	return size, apparent
+}
+
+// Ouvre une archive : son contenu est lu une seule fois puis conservé dans l'arbre
+func (m Model) openArchive(n *FileNode) (Model, tea.Cmd) {
+	if n.Children != nil {
+		m.currentNode = n
+		m.cursor, m.yOffset = 0, 0
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.archiveCancel = cancel
+	m.archiveRead = &atomic.Int64{}
+	m.archiveLoading = n
+	m.state = StateArchive
+	return m, tea.Batch(m.spinner.Tick, loadArchiveCmd(ctx, n, m.archiveRead))
+}
+
+func (m *Model) cancelArchive() {
+	if m.archiveCancel != nil {
+		m.archiveCancel()
+		m.archiveCancel = nil
+	}
+}
+
+// Rattache les entrées lues à l'archive et l'ouvre dans l'explorateur
+func (m Model) applyArchive(msg archiveLoadedMsg) Model {
+	if msg.archive != m.archiveLoading {
+		return m
+	}
+	m.cancelArchive()
+	m.archiveLoading = nil
+	if m.state == StateArchive {
+		m.state = StateBrowsing
+	}
+	if msg.err != nil {
+		m.status = errorStyle.Render("Lecture de l'archive impossible : " + msg.err.Error())
+		return m
+	}
+
+	msg.archive.Children = msg.entries
+	if msg.archive.Children == nil {
+		msg.archive.Children = []*FileNode{}
+	}
+	sortTree(msg.archive, m.sortMode, m.sortReverse, m.sizeOf)
+	m.currentNode = msg.archive
+	m.cursor, m.yOffset = 0, 0
+	return m
+}
+
+func (m Model) updateArchive(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" || msg.String() == "q" {
+		m.cancelArchive()
+		m.archiveLoading = nil
+		m.state = StateBrowsing
+	}
+	return m, nil
+}
+
+func (m Model) viewArchive() string {
+	title := titleStyle.Render("AED - Archive")
+	return fmt.Sprintf("\n  %s  %s\n\n  %s Lecture de l'archive...\n\n%s sur %s lus\n\n  %s",
+		title, pathStyle.Render(m.archiveLoading.Path), m.spinner.View(),
+		countStyle.Render(formatBytes(m.archiveRead.Load())), formatBytes(m.archiveLoading.Apparent),
+		helpStyle.Render("(q/esc: annuler)"))
+}
+
+// Mention ajoutée à une entrée d'archive : taille de l'autre mode (compressée ou décompressée)
+func (m Model) archiveTag(n *FileNode) string {
+	if !n.Virtual {
+		return ""
+	}
+	approx := ""
+	if format := archiveFormat(archiveOf(n).Name); format == archiveTarGz || format == archiveTarBz2 {
+		approx = "≈ "
+	}
+	if m.apparent {
+		return dimStyle.Render(fmt.Sprintf(" (%s%s compressés)", approx, formatBytes(n.Size)))
+	}
+	return dimStyle.Render(fmt.Sprintf(" (%s décompressés)", formatBytes(n.Apparent)))
+}
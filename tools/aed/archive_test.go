@@ -0,0 +1,61 @@
+package aeddsa
+
+import (
+	"archive/tar"
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// La progression d'un tar non compressé avance aussi sur le contenu sauté
+func TestReadTarProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(f)
+	for _, name := range []string{"gros", "dossier/petit"} {
+		data := make([]byte, 64<<10)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(data)
+	}
+	tw.Close()
+	f.Close()
+	info, _ := os.Stat(path)
+
+	var read atomic.Int64
+	archive := &FileNode{Name: "a.tar", Path: path, Apparent: info.Size()}
+	entries, err := readArchive(context.Background(), archive, &read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("%d entrées, attendu 2", len(entries))
+	}
+	if got := read.Load(); got < info.Size()/2 || got > info.Size() {
+		t.Errorf("progression %d octets sur %d", got, info.Size())
+	}
+}
+
+// Le tri s'applique aussi au contenu d'une archive ouverte
+func TestSortTreeArchive(t *testing.T) {
+	root := testRoot("/r")
+	archive := testFile(root, "a.zip", 10)
+	dir := &FileNode{Name: "d", IsDir: true, Virtual: true, Parent: archive}
+	archive.Children = []*FileNode{
+		{Name: "b", Size: 1, Virtual: true, Parent: archive},
+		dir,
+		{Name: "a", Size: 2, Virtual: true, Parent: archive},
+	}
+	dir.Children = []*FileNode{{Name: "z", Virtual: true, Parent: dir}, {Name: "y", Virtual: true, Parent: dir}}
+
+	sortTree(root, sortByName, false, func(n *FileNode) int64 { return n.Size })
+	if archive.Children[0].Name != "a" || dir.Children[0].Name != "y" {
+		t.Errorf("contenu de l'archive non trié : %s, %s", archive.Children[0].Name, dir.Children[0].Name)
+	}
+}
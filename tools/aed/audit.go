@@ -0,0 +1,447 @@
+package aeddsa
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Catégories de constats de l'audit des droits
+const (
+	findSetuid = iota
+	findSetgid
+	findWorldWritable
+	findNoSticky
+	findOrphan
+	findCapability
+	findKinds
+)
+
+// Libellés affichés, puis identifiants stables de l'export
+var (
+	findingLabels = [findKinds]string{"setuid", "setgid", "écriture pour tous", "sans sticky bit", "uid inexistant", "capacités"}
+	findingKeys   = [findKinds]string{"setuid", "setgid", "world-writable", "no-sticky", "orphan-uid", "capability"}
+)
+
+// Noms des capacités Linux, indexés par numéro de bit
+var capNames = []string{
+	"chown", "dac_override", "dac_read_search", "fowner", "fsetid", "kill", "setgid", "setuid", "setpcap",
+	"linux_immutable", "net_bind_service", "net_broadcast", "net_admin", "net_raw", "ipc_lock", "ipc_owner",
+	"sys_module", "sys_rawio", "sys_chroot", "sys_ptrace", "sys_pacct", "sys_admin", "sys_boot", "sys_nice",
+	"sys_resource", "sys_time", "sys_tty_config", "mknod", "lease", "audit_write", "audit_control", "setfcap",
+	"mac_override", "mac_admin", "syslog", "wake_alarm", "block_suspend", "audit_read", "perfmon", "bpf",
+	"checkpoint_restore",
+}
+
+// Élément signalé par l'audit
+type auditFinding struct {
+	node   *FileNode
+	kind   int
+	detail string
+}
+
+// Message envoyé lorsque les capacités des exécutables ont été lues
+type capsFoundMsg struct {
+	done     *atomic.Int64 // compteur de la lecture, qui l'identifie
+	findings []auditFinding
+	err      error
+}
+
+// Droits au format ls (-rwsr-xr-x) à partir du st_mode brut
+func modeString(mode uint32) string {
+	var b strings.Builder
+	switch mode & syscall.S_IFMT {
+	case syscall.S_IFDIR:
+		b.WriteByte('d')
+	case syscall.S_IFLNK:
+		b.WriteByte('l')
+	case syscall.S_IFCHR:
+		b.WriteByte('c')
+	case syscall.S_IFBLK:
+		b.WriteByte('b')
+	case syscall.S_IFIFO:
+		b.WriteByte('p')
+	case syscall.S_IFSOCK:
+		b.WriteByte('s')
+	default:
+		b.WriteByte('-')
+	}
+
+	special := [3]struct {
+		bit        uint32
+		set, unset byte
+	}{{syscall.S_ISUID, 's', 'S'}, {syscall.S_ISGID, 's', 'S'}, {syscall.S_ISVTX, 't', 'T'}}
+	for i := 0; i < 3; i++ {
+		perm := mode >> (6 - 3*i)
+		b.WriteByte("-r"[perm>>2&1])
+		b.WriteByte("-w"[perm>>1&1])
+		exec := perm&1 != 0
+		switch {
+		case mode&special[i].bit != 0 && exec:
+			b.WriteByte(special[i].set)
+		case mode&special[i].bit != 0:
+			b.WriteByte(special[i].unset)
+		case exec:
+			b.WriteByte('x')
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// Constats tirés des droits et du propriétaire d'un élément
+func modeFindings(n *FileNode) []auditFinding {
+	var found []auditFinding
+	fileType := n.Mode & syscall.S_IFMT
+	switch fileType {
+	case syscall.S_IFREG:
+		if n.Mode&syscall.S_ISUID != 0 {
+			found = append(found, auditFinding{n, findSetuid, "exécuté en tant que " + userName(n.Uid)})
+		}
+		if n.Mode&syscall.S_ISGID != 0 {
+			found = append(found, auditFinding{n, findSetgid, "exécuté avec le groupe " + groupName(n.Gid)})
+		}
+		if n.Mode&0o002 != 0 {
+			found = append(found, auditFinding{n, findWorldWritable, "fichier modifiable par tous"})
+		}
+	case syscall.S_IFDIR:
+		if n.Mode&0o002 != 0 && n.Mode&syscall.S_ISVTX == 0 {
+			found = append(found, auditFinding{n, findNoSticky, "chacun peut y supprimer les fichiers des autres"})
+		}
+	}
+	if !userExists(n.Uid) {
+		found = append(found, auditFinding{n, findOrphan, fmt.Sprintf("uid %d", n.Uid)})
+	}
+	return found
+}
+
+// Parcourt le sous-arbre : constats sur les droits et exécutables dont les capacités sont à lire
+// Les éléments illisibles ou sans droits connus (ancien instantané) sont ignorés
+func auditTree(root *FileNode) ([]auditFinding, []*FileNode) {
+	var findings []auditFinding
+	var executables []*FileNode
+	var walk func(n *FileNode)
+	walk = func(n *FileNode) {
+		if !n.ReadError && n.Mode != 0 {
+			findings = append(findings, modeFindings(n)...)
+			if n.Mode&syscall.S_IFMT == syscall.S_IFREG && n.Mode&0o111 != 0 {
+				executables = append(executables, n)
+			}
+		}
+		if !n.IsDir {
+			return
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return findings, executables
+}
+
+// Capacités d'un fichier (attribut security.capability), au format de getcap ; vide s'il n'en a pas
+func fileCapabilities(path string) string {
+	buf := make([]byte, 64)
+	n, err := syscall.Getxattr(path, "security.capability", buf)
+	if err != nil || n < 12 {
+		return ""
+	}
+	magic := binary.LittleEndian.Uint32(buf)
+	permitted := uint64(binary.LittleEndian.Uint32(buf[4:]))
+	inheritable := uint64(binary.LittleEndian.Uint32(buf[8:]))
+	// Révisions 2 et 3 : 64 bits de capacités
+	if magic&0xff000000 >= 0x02000000 && n >= 20 {
+		permitted |= uint64(binary.LittleEndian.Uint32(buf[12:])) << 32
+		inheritable |= uint64(binary.LittleEndian.Uint32(buf[16:])) << 32
+	}
+
+	var names []string
+	for bit := range 64 {
+		if (permitted|inheritable)&(1<<bit) == 0 {
+			continue
+		}
+		if bit < len(capNames) {
+			names = append(names, "cap_"+capNames[bit])
+		} else {
+			names = append(names, fmt.Sprintf("cap_%d", bit))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	flags := ""
+	if magic&1 != 0 {
+		flags += "e"
+	}
+	if inheritable != 0 {
+		flags += "i"
+	}
+	if permitted != 0 {
+		flags += "p"
+	}
+	return strings.Join(names, ",") + "=" + flags
+}
+
+// Commande Tea de lecture des capacités des exécutables en arrière-plan
+func capabilitiesCmd(ctx context.Context, files []*FileNode, done *atomic.Int64) tea.Cmd {
+	return func() tea.Msg {
+		caps := make([]string, len(files))
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, scanWorkers)
+		for i, f := range files {
+			if ctx.Err() != nil {
+				break
+			}
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(i int, path string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				caps[i] = fileCapabilities(path)
+				done.Add(1)
+			}(i, f.Path)
+		}
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return capsFoundMsg{done: done, err: err}
+		}
+
+		var findings []auditFinding
+		for i, c := range caps {
+			if c != "" {
+				findings = append(findings, auditFinding{files[i], findCapability, c})
+			}
+		}
+		return capsFoundMsg{done: done, findings: findings}
+	}
+}
+
+// Trie les constats par catégorie puis par chemin
+func sortFindings(findings []auditFinding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].kind != findings[j].kind {
+			return findings[i].kind < findings[j].kind
+		}
+		return findings[i].node.Path < findings[j].node.Path
+	})
+}
+
+// Lance l'audit du dossier courant ; les capacités arrivent une fois les attributs lus
+func (m Model) startAudit() (Model, tea.Cmd) {
+	m.cancelAudit()
+	m.auditRoot = m.currentNode
+	m.auditFindings, m.auditExecs = auditTree(m.currentNode)
+	sortFindings(m.auditFindings)
+	m.auditFilter = -1
+	m.panel.reset()
+	m.state = StateAudit
+	if m.fromSnapshot {
+		// Les fichiers d'un instantané ne sont pas forcément présents sur ce disque
+		m.auditExecs = nil
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.auditCancel = cancel
+	m.auditRead = &atomic.Int64{}
+	return m, tea.Batch(m.spinner.Tick, capabilitiesCmd(ctx, m.auditExecs, m.auditRead))
+}
+
+func (m *Model) cancelAudit() {
+	if m.auditCancel != nil {
+		m.auditCancel()
+		m.auditCancel = nil
+	}
+}
+
+// Ajoute les capacités trouvées, ignorées si elles concernent un audit précédent
+func (m Model) applyCapabilities(msg capsFoundMsg) Model {
+	if msg.done != m.auditRead {
+		return m
+	}
+	m.cancelAudit()
+	if msg.err == nil && len(msg.findings) > 0 {
+		m.auditFindings = append(m.auditFindings, msg.findings...)
+		sortFindings(m.auditFindings)
+	}
+	return m
+}
+
+// Constats de la catégorie choisie (toutes si le filtre vaut -1)
+func (m Model) visibleFindings() []auditFinding {
+	if m.auditFilter < 0 {
+		return m.auditFindings
+	}
+	var visible []auditFinding
+	for _, f := range m.auditFindings {
+		if f.kind == m.auditFilter {
+			visible = append(visible, f)
+		}
+	}
+	return visible
+}
+
+// Demande le fichier d'export des constats affichés : JSON si l'extension est .json, CSV sinon
+func (m Model) openAuditExportPrompt() (Model, tea.Cmd) {
+	def := strings.TrimSuffix(defaultSnapshotPath(m.auditRoot.Path, m.scannedAt), snapshotExt) + ".audit.csv"
+	return m.openPrompt("Export de l'audit", "Fichier de destination (.csv ou .json)", def, func(m Model, path string) (Model, tea.Cmd) {
+		findings := m.visibleFindings()
+		if err := exportFindingsFile(path, findings); err != nil {
+			m.status = errorStyle.Render("Export impossible : " + err.Error())
+		} else {
+			m.status = fmt.Sprintf("%d constat(s) exporté(s) : %s", len(findings), path)
+		}
+		return m, nil
+	})
+}
+
+// Constat tel qu'exporté
+type findingRecord struct {
+	Kind   string `json:"kind"`
+	Path   string `json:"path"`
+	Mode   string `json:"mode"`
+	Owner  string `json:"owner"`
+	Group  string `json:"group"`
+	Detail string `json:"detail"`
+}
+
+func exportFindingsFile(path string, findings []auditFinding) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := exportFindings(f, findings, strings.EqualFold(filepath.Ext(path), ".json")); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func exportFindings(w io.Writer, findings []auditFinding, asJSON bool) error {
+	records := make([]findingRecord, len(findings))
+	for i, f := range findings {
+		records[i] = findingRecord{Kind: findingKeys[f.kind], Path: f.node.Path, Mode: modeString(f.node.Mode),
+			Owner: userName(f.node.Uid), Group: groupName(f.node.Gid), Detail: f.detail}
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"kind", "path", "mode", "owner", "group", "detail"})
+	for _, r := range records {
+		cw.Write([]string{r.Kind, r.Path, r.Mode, r.Owner, r.Group, r.Detail})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (m Model) updateAudit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	findings := m.visibleFindings()
+	switch msg.String() {
+	case "esc", "q", "p":
+		m.cancelAudit()
+		m.state = StateBrowsing
+		return m, nil
+	case "tab":
+		m.auditFilter++
+		if m.auditFilter == findKinds {
+			m.auditFilter = -1
+		}
+		m.panel.reset()
+		return m, nil
+	case "shift+tab":
+		m.auditFilter--
+		if m.auditFilter < -1 {
+			m.auditFilter = findKinds - 1
+		}
+		m.panel.reset()
+		return m, nil
+	case "e":
+		if len(findings) == 0 {
+			return m, nil
+		}
+		return m.openAuditExportPrompt()
+	case "enter":
+		if m.panel.cursor < len(findings) {
+			n := findings[m.panel.cursor].node
+			if n.Parent == nil {
+				m.currentNode, m.cursor, m.yOffset = n, 0, 0
+				m.state = StateBrowsing
+				return m, nil
+			}
+			return m.revealNode(n), nil
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(findings), m.height-9)
+	return m, nil
+}
+
+func (m Model) viewAudit() string {
+	title := titleStyle.Render("AED - Audit des droits")
+	header := fmt.Sprintf("  %s  %s  %s\n", title, pathStyle.Render(m.auditRoot.Path),
+		infoStyle.Render(fmt.Sprintf("%d constat(s)", len(m.auditFindings))))
+
+	// Onglets de filtre avec le nombre de constats par catégorie
+	var counts [findKinds]int
+	for _, f := range m.auditFindings {
+		counts[f.kind]++
+	}
+	tabs := []string{fmt.Sprintf("tous (%d)", len(m.auditFindings))}
+	for kind, label := range findingLabels {
+		tabs = append(tabs, fmt.Sprintf("%s (%d)", label, counts[kind]))
+	}
+	sel := m.auditFilter + 1
+	tabs[sel] = pathStyle.Render("[" + tabs[sel] + "]")
+	header += "  " + strings.Join(tabs, " ") + "\n"
+
+	if m.auditCancel != nil {
+		header += fmt.Sprintf("  %s Lecture des capacités... %s\n", m.spinner.View(),
+			countStyle.Render(fmt.Sprintf("%d / %d", m.auditRead.Load(), len(m.auditExecs))))
+	} else if m.fromSnapshot {
+		header += dimStyle.Render("  Capacités non vérifiées : arbre chargé depuis un instantané") + "\n"
+	}
+
+	findings := m.visibleFindings()
+	var content string
+	if len(findings) == 0 {
+		content = "  Aucun constat."
+	} else {
+		rows := make([]string, len(findings))
+		for i, f := range findings {
+			name := f.node.Path
+			if f.node.IsDir {
+				name += "/"
+			}
+			rows[i] = fmt.Sprintf("%s  %s  %-10.10s  %s  %s", errorStyle.Render(fmt.Sprintf("%-18s", findingLabels[f.kind])),
+				modeString(f.node.Mode), userName(f.node.Uid), name, dimStyle.Render(f.detail))
+		}
+		content = m.panel.render(rows, m.height-9, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • tab: filtrer par catégorie • enter: aller à l'élément • e: exporter • esc: retour")
+	if m.status != "" {
+		footer += "\n  " + m.status
+	}
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
@@ -0,0 +1,207 @@
+package aeddsa
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Modes de tri de l'explorateur
+type sortMode int
+
+const (
+	sortBySize sortMode = iota
+	sortByName
+	sortByCount
+	sortByMtime
+	sortByExt
+	sortModeCount
+)
+
+var sortLabels = [...]string{"taille", "nom", "éléments", "date", "extension"}
+
+// Colonnes optionnelles de l'explorateur, activées par les touches 1 à 4
+type columnSet struct {
+	count   bool
+	mtime   bool
+	owner   bool
+	percent bool
+}
+
+// Compare deux éléments selon le mode ; l'ordre naturel est décroissant pour les valeurs numériques
+func sortLess(mode sortMode, size func(*FileNode) int64, a, b *FileNode) bool {
+	switch mode {
+	case sortByName:
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	case sortByCount:
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+	case sortByMtime:
+		if ma, mb := a.lastModified(), b.lastModified(); !ma.Equal(mb) {
+			return ma.After(mb)
+		}
+	case sortByExt:
+		ea, eb := strings.ToLower(filepath.Ext(a.Name)), strings.ToLower(filepath.Ext(b.Name))
+		if ea != eb {
+			return ea < eb
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+	if sa, sb := size(a), size(b); sa != sb {
+		return sa > sb
+	}
+	return a.Name < b.Name
+}
+
+// Trie les enfants directs d'un dossier selon le mode choisi
+func sortChildren(node *FileNode, mode sortMode, reverse bool, size func(*FileNode) int64) {
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		if reverse {
+			return sortLess(mode, size, node.Children[j], node.Children[i])
+		}
+		return sortLess(mode, size, node.Children[i], node.Children[j])
+	})
+}
+
+// Trie récursivement les enfants de l'arbre selon le mode choisi, contenu des archives ouvertes compris
+func sortTree(node *FileNode, mode sortMode, reverse bool, size func(*FileNode) int64) {
+	sortChildren(node, mode, reverse, size)
+	for _, child := range node.Children {
+		if len(child.Children) > 0 {
+			sortTree(child, mode, reverse, size)
+		}
+	}
+}
+
+// Applique le mode de tri courant à tout l'arbre en gardant l'élément sélectionné sous le curseur
+func (m Model) resort() Model {
+	return m.keepSelection(func() {
+		sortTree(m.root, m.sortMode, m.sortReverse, m.sizeOf)
+	})
+}
+
+// Réordonne les éléments affichés puis replace le curseur sur l'élément qui était sélectionné
+func (m Model) keepSelection(reorder func()) Model {
+	items := m.getDisplayItems()
+	var selected *FileNode
+	if m.cursor < len(items) {
+		selected = items[m.cursor]
+	}
+
+	reorder()
+
+	for i, item := range m.getDisplayItems() {
+		if selected != nil && item.Path == selected.Path {
+			m.cursor = i
+			break
+		}
+	}
+	visibleHeight := m.listHeight()
+	if m.cursor < m.yOffset || m.cursor >= m.yOffset+visibleHeight {
+		m.yOffset = max(m.cursor-visibleHeight/2, 0)
+	}
+	return m
+}
+
+// Libellé du tri affiché dans l'en-tête
+func (m Model) sortLabel() string {
+	arrow := "↓"
+	if m.sortReverse {
+		arrow = "↑"
+	}
+	return "tri : " + sortLabels[m.sortMode] + " " + arrow
+}
+
+// Colonnes optionnelles d'un élément de la liste (vides pour l'entrée "..")
+func (m Model) columnsFor(item *FileNode) string {
+	blank := item.Name == ".."
+	var cols []string
+
+	if m.columns.count {
+		s := ""
+		if item.IsDir && !blank {
+			s = strconv.FormatInt(item.Count, 10)
+		}
+		cols = append(cols, fmt.Sprintf("%8s", s))
+	}
+	if m.columns.mtime {
+		s := ""
+		if t := item.lastModified(); !blank && !t.IsZero() {
+			s = t.Format("2006-01-02 15:04")
+		}
+		cols = append(cols, fmt.Sprintf("%16s", s))
+	}
+	if m.columns.owner {
+		s := ""
+		if !blank {
+			s = userName(item.Uid)
+		}
+		cols = append(cols, fmt.Sprintf("%-10.10s", s))
+	}
+	if m.columns.percent {
+		s := ""
+		if total := m.currentTotal(); !blank && total > 0 {
+			s = fmt.Sprintf("%.1f%%", float64(m.sizeOf(item))/float64(total)*100)
+		}
+		cols = append(cols, fmt.Sprintf("%6s", s))
+	}
+
+	if len(cols) == 0 {
+		return ""
+	}
+	return dimStyle.Render(strings.Join(cols, "  ")) + "  "
+}
+
+// Noms d'utilisateurs et de groupes (/etc/passwd, /etc/group), résolus une seule fois par identifiant
+var (
+	namesMu    sync.Mutex
+	userNames  = map[uint32]string{}
+	groupNames = map[uint32]string{}
+	// uid absents de la base des utilisateurs (compte supprimé)
+	unknownUsers = map[uint32]bool{}
+)
+
+func userName(uid uint32) string {
+	namesMu.Lock()
+	defer namesMu.Unlock()
+	if name, ok := userNames[uid]; ok {
+		return name
+	}
+	name := strconv.FormatUint(uint64(uid), 10)
+	u, err := user.LookupId(name)
+	if err == nil {
+		name = u.Username
+	}
+	var unknown user.UnknownUserIdError
+	unknownUsers[uid] = errors.As(err, &unknown)
+	userNames[uid] = name
+	return name
+}
+
+// Indique si l'uid correspond à un utilisateur existant
+func userExists(uid uint32) bool {
+	userName(uid)
+	namesMu.Lock()
+	defer namesMu.Unlock()
+	return !unknownUsers[uid]
+}
+
+func groupName(gid uint32) string {
+	namesMu.Lock()
+	defer namesMu.Unlock()
+	if name, ok := groupNames[gid]; ok {
+		return name
+	}
+	name := strconv.FormatUint(uint64(gid), 10)
+	if g, err := user.LookupGroupId(name); err == nil {
+		name = g.Name
+	}
+	groupNames[gid] = name
+	return name
+}
@@ -0,0 +1,482 @@
+package aeddsa
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// État d'une entrée de la comparaison de deux dossiers
+const (
+	cmpIdentical = iota
+	cmpDiffers
+	cmpOnlyLeft
+	cmpOnlyRight
+)
+
+// Résultat de la comparaison du contenu de deux fichiers de même taille
+const (
+	hashUnchecked = iota
+	hashSame
+	hashDiffers
+	hashFailed
+)
+
+// Entrée de l'arbre fusionné : le même chemin relatif de part et d'autre
+type cmpNode struct {
+	name     string
+	left     *FileNode // nil si l'entrée n'existe qu'à droite
+	right    *FileNode // nil si l'entrée n'existe qu'à gauche
+	status   int
+	hash     int
+	delta    int64 // écart de taille apparente (droite - gauche), cumulé pour un dossier
+	diffs    int64 // entrées différentes contenues, récursivement
+	children []*cmpNode
+	parent   *cmpNode
+}
+
+// Message envoyé lorsque le second dossier a été scanné
+type cmpScannedMsg struct {
+	progress *scanProgress // progression du scan, qui l'identifie
+	root     *FileNode
+	err      error
+}
+
+// Message envoyé lorsque le contenu des fichiers suspects a été comparé
+type cmpHashedMsg struct {
+	progress *dupProgress
+	results  map[*cmpNode]int
+	err      error
+}
+
+func (n *cmpNode) isDir() bool {
+	return (n.left != nil && n.left.IsDir) || (n.right != nil && n.right.IsDir)
+}
+
+// Taille apparente d'un côté, 0 s'il est absent
+func sideSize(n *FileNode) int64 {
+	if n == nil {
+		return 0
+	}
+	return n.Apparent
+}
+
+// Fichiers présents des deux côtés avec la même taille : seul le contenu peut les départager
+func (n *cmpNode) sameSizeFiles() bool {
+	return n.left != nil && n.right != nil && !n.left.IsDir && !n.right.IsDir && n.left.Apparent == n.right.Apparent
+}
+
+// Même taille mais date de modification différente : copie à vérifier par le contenu
+func (n *cmpNode) suspicious() bool {
+	return n.sameSizeFiles() && n.hash == hashUnchecked && !n.left.ModTime.Equal(n.right.ModTime)
+}
+
+// Fusionne deux arbres par nom d'entrée
+func mergeTrees(name string, left, right *FileNode, parent *cmpNode) *cmpNode {
+	n := &cmpNode{name: name, left: left, right: right, parent: parent}
+
+	byName := make(map[string]*cmpNode)
+	if left != nil && left.IsDir {
+		for _, child := range left.Children {
+			c := &cmpNode{name: child.Name, left: child}
+			byName[child.Name] = c
+			n.children = append(n.children, c)
+		}
+	}
+	if right != nil && right.IsDir {
+		for _, child := range right.Children {
+			if c, ok := byName[child.Name]; ok {
+				c.right = child
+				continue
+			}
+			c := &cmpNode{name: child.Name, right: child}
+			n.children = append(n.children, c)
+		}
+	}
+	for i, c := range n.children {
+		n.children[i] = mergeTrees(c.name, c.left, c.right, n)
+	}
+
+	sort.Slice(n.children, func(i, j int) bool {
+		a, b := n.children[i], n.children[j]
+		sa, sb := max(sideSize(a.left), sideSize(a.right)), max(sideSize(b.left), sideSize(b.right))
+		if sa != sb {
+			return sa > sb
+		}
+		return a.name < b.name
+	})
+	return n
+}
+
+// Calcule l'état, l'écart de taille et le nombre de différences de chaque entrée
+func finalizeCmp(n *cmpNode) {
+	n.delta = sideSize(n.right) - sideSize(n.left)
+	n.diffs = 0
+	for _, child := range n.children {
+		finalizeCmp(child)
+		if len(child.children) > 0 {
+			n.diffs += child.diffs
+		} else if child.status != cmpIdentical {
+			n.diffs++
+		}
+	}
+
+	switch {
+	case n.left == nil:
+		n.status = cmpOnlyRight
+	case n.right == nil:
+		n.status = cmpOnlyLeft
+	case n.left.IsDir != n.right.IsDir:
+		n.status = cmpDiffers
+	case n.left.IsDir:
+		n.status = cmpIdentical
+		if n.diffs > 0 {
+			n.status = cmpDiffers
+		}
+	case n.left.Apparent != n.right.Apparent || n.hash == hashDiffers:
+		n.status = cmpDiffers
+	default:
+		n.status = cmpIdentical
+	}
+}
+
+// Commande Tea de scan du second dossier
+func scanCompareCmd(ctx context.Context, path string, opts ScanOptions, progress *scanProgress) tea.Cmd {
+	return func() tea.Msg {
+		root, _, err := scanRecursively(ctx, path, opts, progress)
+		return cmpScannedMsg{progress: progress, root: root, err: err}
+	}
+}
+
+// Fichiers de même taille à départager par le contenu sous une entrée
+func hashCandidates(n *cmpNode) []*cmpNode {
+	var found []*cmpNode
+	var walk func(n *cmpNode)
+	walk = func(n *cmpNode) {
+		if n.sameSizeFiles() && n.hash == hashUnchecked {
+			found = append(found, n)
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// Commande Tea de comparaison du contenu (SHA-256 complet des deux côtés)
+func hashCompareCmd(ctx context.Context, pairs []*cmpNode, progress *dupProgress) tea.Cmd {
+	return func() tea.Msg {
+		results := make(map[*cmpNode]int, len(pairs))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, scanWorkers)
+		for _, p := range pairs {
+			if ctx.Err() != nil {
+				break
+			}
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(p *cmpNode) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				result := hashFailed
+				left, errLeft := hashFile(ctx, p.left.Path, false, progress)
+				right, errRight := hashFile(ctx, p.right.Path, false, progress)
+				if errLeft == nil && errRight == nil {
+					result = hashDiffers
+					if left == right {
+						result = hashSame
+					}
+				}
+				mu.Lock()
+				results[p] = result
+				mu.Unlock()
+			}(p)
+		}
+		wg.Wait()
+		return cmpHashedMsg{progress: progress, results: results, err: ctx.Err()}
+	}
+}
+
+// Demande le dossier à comparer avec le dossier courant
+func (m Model) openTreeComparePrompt() (Model, tea.Cmd) {
+	if !m.currentNode.IsDir || m.currentNode.Virtual {
+		m.status = errorStyle.Render("La comparaison porte sur un dossier du disque")
+		return m, nil
+	}
+	return m.openPrompt("Comparer deux dossiers", "Dossier à comparer avec "+m.currentNode.Path, m.currentNode.Path,
+		func(m Model, path string) (Model, tea.Cmd) {
+			abs, err := filepath.Abs(path)
+			if err == nil {
+				var info os.FileInfo
+				if info, err = os.Stat(abs); err == nil && !info.IsDir() {
+					err = fmt.Errorf("%s n'est pas un dossier", abs)
+				}
+			}
+			if err != nil {
+				m.status = errorStyle.Render(err.Error())
+				return m, nil
+			}
+			if abs == m.currentNode.Path {
+				m.status = errorStyle.Render("Choisissez un autre dossier que " + abs)
+				return m, nil
+			}
+			return m.startTreeCompare(abs)
+		})
+}
+
+// Scanne le second dossier ; le dossier courant sert de référence (gauche)
+func (m Model) startTreeCompare(path string) (Model, tea.Cmd) {
+	m.cancelTreeCompare()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cmpCancel = cancel
+	m.cmpProgress = newScanProgress()
+	m.cmpLeft = m.currentNode
+	m.cmpRightPath = path
+	m.state = StateCompareScanning
+	return m, tea.Batch(m.spinner.Tick, scanCompareCmd(ctx, path, m.options, m.cmpProgress))
+}
+
+func (m *Model) cancelTreeCompare() {
+	if m.cmpCancel != nil {
+		m.cmpCancel()
+		m.cmpCancel = nil
+	}
+	if m.cmpHashCancel != nil {
+		m.cmpHashCancel()
+		m.cmpHashCancel = nil
+	}
+}
+
+// Construit l'arbre fusionné une fois le second dossier scanné
+func (m Model) applyCompareScan(msg cmpScannedMsg) Model {
+	if msg.progress != m.cmpProgress {
+		return m
+	}
+	m.cmpCancel = nil
+	if msg.err != nil {
+		m.state = StateBrowsing
+		if msg.err != context.Canceled {
+			m.status = errorStyle.Render("Scan impossible : " + msg.err.Error())
+		}
+		return m
+	}
+
+	m.cmpRoot = mergeTrees(m.cmpLeft.Path, m.cmpLeft, msg.root, nil)
+	finalizeCmp(m.cmpRoot)
+	m.cmpCurrent = m.cmpRoot
+	m.cmpOnlyDiffs = false
+	m.panel.reset()
+	m.state = StateCompare
+	return m
+}
+
+// Compare le contenu des fichiers de même taille sous l'entrée choisie
+func (m Model) startCompareHash(n *cmpNode) (Model, tea.Cmd) {
+	if m.cmpHashCancel != nil {
+		return m, nil
+	}
+	pairs := hashCandidates(n)
+	if len(pairs) == 0 {
+		m.status = "Aucun fichier de même taille à vérifier"
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cmpHashCancel = cancel
+	m.cmpHashProgress = &dupProgress{}
+	m.cmpHashTotal = len(pairs)
+	m.status = ""
+	return m, tea.Batch(m.spinner.Tick, hashCompareCmd(ctx, pairs, m.cmpHashProgress))
+}
+
+// Reporte les comparaisons de contenu et recalcule les états
+func (m Model) applyCompareHash(msg cmpHashedMsg) Model {
+	if msg.progress != m.cmpHashProgress {
+		return m
+	}
+	m.cmpHashCancel = nil
+	differs := 0
+	for n, result := range msg.results {
+		n.hash = result
+		if result == hashDiffers {
+			differs++
+		}
+	}
+	finalizeCmp(m.cmpRoot)
+	m.status = fmt.Sprintf("%d fichier(s) vérifié(s), %d au contenu différent", len(msg.results), differs)
+	if msg.err != nil {
+		m.status = "Vérification interrompue : " + m.status
+	}
+	return m
+}
+
+// Entrées affichées du dossier courant de la comparaison
+func (m Model) cmpItems() []*cmpNode {
+	if !m.cmpOnlyDiffs {
+		return m.cmpCurrent.children
+	}
+	var items []*cmpNode
+	for _, c := range m.cmpCurrent.children {
+		if c.status != cmpIdentical {
+			items = append(items, c)
+		}
+	}
+	return items
+}
+
+// Remonte au dossier parent en replaçant le curseur sur le dossier quitté
+func (m Model) cmpLeave() Model {
+	from := m.cmpCurrent
+	m.cmpCurrent = from.parent
+	m.panel.reset()
+	for i, c := range m.cmpItems() {
+		if c == from {
+			m.panel.move(i, len(m.cmpItems()), m.height-10)
+			break
+		}
+	}
+	return m
+}
+
+func (m Model) updateCompareScanning(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" || msg.String() == "q" {
+		m.cancelTreeCompare()
+		m.state = StateBrowsing
+	}
+	return m, nil
+}
+
+func (m Model) updateCompare(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	items := m.cmpItems()
+	m.status = ""
+	switch msg.String() {
+	case "esc", "q":
+		m.cancelTreeCompare()
+		m.state = StateBrowsing
+		return m, nil
+	case "enter", "right", "l":
+		if m.panel.cursor < len(items) && len(items[m.panel.cursor].children) > 0 {
+			m.cmpCurrent = items[m.panel.cursor]
+			m.panel.reset()
+		}
+		return m, nil
+	case "left", "h", "backspace":
+		if m.cmpCurrent.parent != nil {
+			m = m.cmpLeave()
+		}
+		return m, nil
+	case "f":
+		m.cmpOnlyDiffs = !m.cmpOnlyDiffs
+		m.panel.reset()
+		return m, nil
+	case "c":
+		target := m.cmpCurrent
+		if m.panel.cursor < len(items) {
+			target = items[m.panel.cursor]
+		}
+		return m.startCompareHash(target)
+	case "C":
+		return m.startCompareHash(m.cmpRoot)
+	}
+	m.panel.handleKey(msg.String(), len(items), m.height-10)
+	return m, nil
+}
+
+func (m Model) viewCompareScanning() string {
+	title := titleStyle.Render("AED - Comparaison de dossiers")
+	return fmt.Sprintf("\n  %s  %s\n\n  %s Analyse de %s...\n\n%s fichiers scannés\n%s comptés\n\n  %s",
+		title, pathStyle.Render(m.cmpLeft.Path), m.spinner.View(), pathStyle.Render(m.cmpRightPath),
+		countStyle.Render(fmt.Sprintf("%d", m.cmpProgress.files.Load())),
+		countStyle.Render(formatBytes(m.cmpProgress.bytes.Load())),
+		helpStyle.Render("(q/esc: annuler)"))
+}
+
+// Symbole et style de l'état d'une entrée
+func cmpStatusLabel(n *cmpNode) string {
+	switch n.status {
+	case cmpOnlyLeft:
+		return shrinkStyle.Render("← gauche seul ")
+	case cmpOnlyRight:
+		return growStyle.Render("→ droite seul ")
+	case cmpDiffers:
+		return sparseStyle.Render("≠ différent   ")
+	}
+	return dimStyle.Render("= identique   ")
+}
+
+// Précisions sur une entrée : différences contenues, vérification du contenu
+func cmpDetail(n *cmpNode) string {
+	switch {
+	case len(n.children) > 0 && n.diffs > 0:
+		return dimStyle.Render(fmt.Sprintf(" (%d différence(s))", n.diffs))
+	case n.left != nil && n.right != nil && n.left.IsDir != n.right.IsDir:
+		return sparseStyle.Render(" (dossier d'un côté, fichier de l'autre)")
+	case n.hash == hashSame:
+		return dimStyle.Render(" (contenu identique)")
+	case n.hash == hashDiffers:
+		return errorStyle.Render(" (contenu différent)")
+	case n.hash == hashFailed:
+		return errorStyle.Render(" (lecture impossible)")
+	case n.suspicious():
+		return sparseStyle.Render(" ? dates différentes")
+	}
+	return ""
+}
+
+func (m Model) viewCompare() string {
+	title := titleStyle.Render("AED - Comparaison de dossiers")
+	rel := "."
+	for n := m.cmpCurrent; n.parent != nil; n = n.parent {
+		rel = filepath.Join(n.name, rel)
+	}
+	header := fmt.Sprintf("  %s  %s ↔ %s\n  %s  %s", title, pathStyle.Render(m.cmpLeft.Path), pathStyle.Render(m.cmpRightPath),
+		infoStyle.Render(fmt.Sprintf("%s  écart %s", rel, formatDelta(m.cmpCurrent.delta))),
+		dimStyle.Render(fmt.Sprintf("%d différence(s) au total", m.cmpRoot.diffs)))
+	if m.cmpOnlyDiffs {
+		header += "  " + pathStyle.Render("[différences seulement]")
+	}
+	if m.cmpHashCancel != nil {
+		header += fmt.Sprintf("\n  %s Vérification du contenu... %s", m.spinner.View(),
+			countStyle.Render(fmt.Sprintf("%d / %d fichiers", m.cmpHashProgress.files.Load()/2, m.cmpHashTotal)))
+	}
+
+	items := m.cmpItems()
+	var content string
+	if len(items) == 0 {
+		content = "  Aucune entrée."
+	} else {
+		rows := make([]string, len(items))
+		for i, n := range items {
+			name := n.name
+			if n.isDir() {
+				name += "/"
+			}
+			delta := ""
+			if n.delta != 0 {
+				delta = formatDelta(n.delta)
+			}
+			left, right := "-", "-"
+			if n.left != nil {
+				left = formatBytes(n.left.Apparent)
+			}
+			if n.right != nil {
+				right = formatBytes(n.right.Apparent)
+			}
+			rows[i] = fmt.Sprintf("%s %10s %10s %11s  %s%s", cmpStatusLabel(n), left, right, delta, name, cmpDetail(n))
+		}
+		content = dimStyle.Render(fmt.Sprintf("  %-14s %10s %10s %11s  %s", "état", "gauche", "droite", "écart", "nom")) + "\n" +
+			m.panel.render(rows, m.height-10, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓/←/→: naviguer • f: différences seulement • c: vérifier le contenu de la sélection • C: tout vérifier • esc: retour")
+	if m.status != "" {
+		footer += "\n  " + m.status
+	}
+	return fmt.Sprintf("\n%s\n\n%s\n%s", header, content, footer)
+}
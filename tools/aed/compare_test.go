@@ -0,0 +1,93 @@
+package aeddsa
+
+import "testing"
+
+// Entrée fusionnée à partir de son nom, dans les enfants d'un nœud
+func cmpChild(n *cmpNode, name string) *cmpNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestMergeTrees(t *testing.T) {
+	left := testRoot("/gauche")
+	testFile(left, "identique", 100)
+	testFile(left, "modifié", 100)
+	testFile(left, "seul-gauche", 50)
+	lsub := testDir(left, "sous")
+	testFile(lsub, "a", 10)
+	testDir(left, "type")
+
+	right := testRoot("/droite")
+	testFile(right, "identique", 100)
+	testFile(right, "modifié", 300)
+	testFile(right, "seul-droite", 70)
+	rsub := testDir(right, "sous")
+	testFile(rsub, "a", 10)
+	testFile(right, "type", 5)
+
+	root := mergeTrees("", left, right, nil)
+	finalizeCmp(root)
+
+	tests := []struct {
+		name   string
+		status int
+		delta  int64
+	}{
+		{"identique", cmpIdentical, 0},
+		{"modifié", cmpDiffers, 200},
+		{"seul-gauche", cmpOnlyLeft, -50},
+		{"seul-droite", cmpOnlyRight, 70},
+		{"sous", cmpIdentical, 0},
+		{"type", cmpDiffers, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cmpChild(root, tt.name)
+			if c == nil {
+				t.Fatal("entrée absente de l'arbre fusionné")
+			}
+			if c.parent != root {
+				t.Error("parent non renseigné")
+			}
+			if c.status != tt.status || c.delta != tt.delta {
+				t.Errorf("état %d écart %d, attendu %d et %d", c.status, c.delta, tt.status, tt.delta)
+			}
+		})
+	}
+
+	if len(root.children) != len(tests) {
+		t.Errorf("%d entrées fusionnées, attendu %d", len(root.children), len(tests))
+	}
+	if root.status != cmpDiffers || root.diffs != 4 {
+		t.Errorf("racine : état %d, %d différence(s), attendu %d et 4", root.status, root.diffs, cmpDiffers)
+	}
+	// Tri par taille décroissante (plus grand des deux côtés)
+	if first := root.children[0].name; first != "modifié" {
+		t.Errorf("première entrée %q, attendu %q", first, "modifié")
+	}
+}
+
+// Deux fichiers de même taille ne diffèrent qu'une fois leur contenu comparé
+func TestFinalizeCmpHash(t *testing.T) {
+	left, right := testRoot("/g"), testRoot("/d")
+	testFile(left, "f", 10)
+	f := testFile(right, "f", 10)
+	f.ModTime = f.ModTime.Add(1)
+
+	root := mergeTrees("", left, right, nil)
+	finalizeCmp(root)
+	c := root.children[0]
+	if c.status != cmpIdentical || !c.suspicious() {
+		t.Fatalf("avant comparaison : état %d, suspect %v", c.status, c.suspicious())
+	}
+
+	c.hash = hashDiffers
+	finalizeCmp(root)
+	if c.status != cmpDiffers || root.status != cmpDiffers || c.suspicious() {
+		t.Errorf("après comparaison : état %d, racine %d, suspect %v", c.status, root.status, c.suspicious())
+	}
+}
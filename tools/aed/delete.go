@@ -0,0 +1,327 @@
+package aeddsa
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Message envoyé lorsque la suppression (ou la mise à la corbeille) est terminée
+type deleteDoneMsg struct {
+	removed []*FileNode
+	errs    []error
+	trash   bool
+}
+
+// Marque ou démarque l'élément sous le curseur puis passe au suivant
+func (m Model) toggleMark() Model {
+	items := m.getDisplayItems()
+	if m.cursor >= len(items) {
+		return m
+	}
+	item := items[m.cursor]
+	if item.Virtual {
+		m.status = errorStyle.Render("Les entrées d'archive ne peuvent pas être marquées")
+		return m
+	}
+	if item.Name != "." && item.Name != ".." {
+		if _, ok := m.marked[item.Path]; ok {
+			delete(m.marked, item.Path)
+		} else {
+			m.marked[item.Path] = item
+		}
+	}
+	if m.cursor < len(items)-1 {
+		m.cursor++
+		if visibleHeight := m.listHeight(); m.cursor >= m.yOffset+visibleHeight {
+			m.yOffset = m.cursor - visibleHeight + 1
+		}
+	}
+	return m
+}
+
+// Éléments à supprimer : les marques, ou à défaut l'élément sous le curseur
+// Un élément dont un dossier parent est aussi marqué est ignoré
+func (m Model) deleteTargets() []*FileNode {
+	var targets []*FileNode
+	for _, n := range m.marked {
+		targets = append(targets, n)
+	}
+	if len(targets) == 0 {
+		items := m.getDisplayItems()
+		if m.cursor < len(items) && items[m.cursor].Name != "." && items[m.cursor].Name != ".." {
+			targets = append(targets, items[m.cursor])
+		}
+	}
+
+	var kept []*FileNode
+	for _, n := range targets {
+		covered := false
+		for p := n.Parent; p != nil; p = p.Parent {
+			if _, ok := m.marked[p.Path]; ok {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			kept = append(kept, n)
+		}
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		if a, b := m.sizeOf(kept[i]), m.sizeOf(kept[j]); a != b {
+			return a > b
+		}
+		return kept[i].Path < kept[j].Path
+	})
+	return kept
+}
+
+// Ouvre la confirmation de suppression, la vue courante est rétablie ensuite
+func (m Model) openDeleteConfirm() Model {
+	if m.fromSnapshot {
+		m.status = errorStyle.Render("Suppression impossible depuis un instantané : relancez un scan")
+		return m
+	}
+	m.pending = m.deleteTargets()
+	if len(m.pending) == 0 {
+		return m
+	}
+	for _, n := range m.pending {
+		if n.Virtual {
+			m.pending = nil
+			m.status = errorStyle.Render("Suppression impossible à l'intérieur d'une archive")
+			return m
+		}
+	}
+	m.returnState = m.state
+	m.panel.reset()
+	m.state = StateConfirmDelete
+	return m
+}
+
+// Commande Tea de suppression définitive ou de mise à la corbeille
+func deleteCmd(nodes []*FileNode, trash bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := deleteDoneMsg{trash: trash}
+		for _, n := range nodes {
+			var err error
+			if trash {
+				err = moveToTrash(n.Path)
+			} else {
+				err = os.RemoveAll(n.Path)
+			}
+			if err != nil {
+				msg.errs = append(msg.errs, err)
+				continue
+			}
+			msg.removed = append(msg.removed, n)
+		}
+		return msg
+	}
+}
+
+func (m Model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "n":
+		m.pending = nil
+		m.state = m.returnState
+		return m, nil
+	case "enter", "t":
+		m.state = m.returnState
+		m.status = "Mise à la corbeille..."
+		return m, deleteCmd(m.pending, true)
+	case "S":
+		m.state = m.returnState
+		m.status = "Suppression..."
+		return m, deleteCmd(m.pending, false)
+	}
+	m.panel.handleKey(msg.String(), len(m.pending), m.height-9)
+	return m, nil
+}
+
+func (m Model) viewConfirmDelete() string {
+	total := freedSpace(m.pending, m.apparent)
+	rows := make([]string, len(m.pending))
+	for i, n := range m.pending {
+		name := n.Path
+		if n.IsDir {
+			name += "/"
+		}
+		rows[i] = fmt.Sprintf("%10s  %s", formatBytes(m.sizeOf(n)), name)
+	}
+
+	title := titleStyle.Render("AED - Suppression")
+	header := fmt.Sprintf("  %s  %s\n", title, infoStyle.Render(fmt.Sprintf("%d élément(s), %s libérés", len(m.pending), formatBytes(total))))
+	content := m.panel.render(rows, m.height-9, m.width)
+	footer := helpStyle.Render("\nenter/t: mettre à la corbeille • S: supprimer définitivement • esc: annuler")
+	warning := errorStyle.Render("  La suppression définitive est irréversible.")
+
+	return fmt.Sprintf("\n%s\n%s\n\n%s%s", header, content, warning, footer)
+}
+
+// Retire les éléments supprimés de l'arbre et répercute leur taille sur tous les dossiers parents
+func (m Model) applyDelete(msg deleteDoneMsg) Model {
+	freed := freedSpace(msg.removed, m.apparent)
+	removed := make(map[*FileNode]bool)
+	for _, n := range msg.removed {
+		removed[n] = true
+		delete(m.marked, n.Path)
+		detachNode(n)
+	}
+	relinkRemaining(m.root, msg.removed)
+
+	m = m.forgetRemoved(removed)
+
+	verb := "supprimé(s)"
+	if msg.trash {
+		verb = "mis à la corbeille"
+	}
+	m.status = fmt.Sprintf("%d élément(s) %s, %s libérés", len(msg.removed), verb, formatBytes(freed))
+	if len(msg.errs) > 0 {
+		var errs []string
+		for _, err := range msg.errs {
+			errs = append(errs, err.Error())
+		}
+		m.status += "\n  " + errorStyle.Render(fmt.Sprintf("%d échec(s) : %s", len(msg.errs), strings.Join(errs, " ; ")))
+	}
+	m.pending = nil
+	return m.refreshAgeFilter()
+}
+
+// Quitte le dossier affiché s'il a disparu avec un de ses parents, retire les nœuds des doublons et recalcule les suggestions
+func (m Model) forgetRemoved(removed map[*FileNode]bool) Model {
+	for p := m.currentNode; p != nil; p = p.Parent {
+		if removed[p] && p.Parent != nil {
+			m.currentNode = p.Parent
+		}
+	}
+	m.pruneDuplicates(removed)
+	if m.suggestRoot != nil {
+		for p := m.suggestRoot; p != nil; p = p.Parent {
+			if removed[p] {
+				m.suggestRoot = m.currentNode
+			}
+		}
+		m = m.refreshSuggestions()
+	}
+	if items := m.getDisplayItems(); m.cursor >= len(items) {
+		m.cursor = len(items) - 1
+		if m.yOffset > m.cursor {
+			m.yOffset = m.cursor
+		}
+	}
+	return m
+}
+
+// Détache un nœud de son parent et soustrait sa taille jusqu'à la racine
+func detachNode(n *FileNode) {
+	parent := n.Parent
+	if parent == nil {
+		return
+	}
+	for i, child := range parent.Children {
+		if child == n {
+			parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+			break
+		}
+	}
+	size, apparent := countedSize(n)
+	shrinkAncestors(n, size, apparent)
+	for p := parent; p != nil; p = p.Parent {
+		p.Count -= 1 + n.Count
+		p.Unreadable -= unreadable(n)
+	}
+}
+
+// Soustrait l'espace libéré de tous les dossiers parents du nœud
+func shrinkAncestors(n *FileNode, size, apparent int64) {
+	for p := n.Parent; p != nil; p = p.Parent {
+		p.Size = max(p.Size-size, 0)
+		p.Apparent = max(p.Apparent-apparent, 0)
+	}
+}
+
+// Taille d'un nœud comptée dans celle de ses dossiers parents : nulle pour un lien physique déjà compté via un autre lien
+func countedSize(n *FileNode) (int64, int64) {
+	if n.Shared {
+		return 0, 0
+	}
+	return n.Size, n.Apparent
+}
+
+// Appelle fn pour chaque fichier des sous-arbres (entrées d'archive exclues)
+func walkFiles(nodes []*FileNode, fn func(n *FileNode)) {
+	for _, n := range nodes {
+		switch {
+		case n.Virtual:
+		case n.IsDir:
+			walkFiles(n.Children, fn)
+		default:
+			fn(n)
+		}
+	}
+}
+
+// Espace libéré par la disparition des nœuds : un fichier à plusieurs liens physiques
+// ne compte que si tous ses liens connus disparaissent avec lui
+func freedSpace(nodes []*FileNode, apparent bool) int64 {
+	var freed int64
+	links := make(map[fileID]uint64)
+	files := make(map[fileID]*FileNode)
+	walkFiles(nodes, func(n *FileNode) {
+		if n.Ino == 0 || n.Nlink <= 1 {
+			freed += nodeSize(n, apparent)
+			return
+		}
+		id := fileID{dev: n.Dev, ino: n.Ino}
+		links[id]++
+		files[id] = n
+	})
+	for id, removed := range links {
+		if removed >= files[id].Nlink {
+			freed += nodeSize(files[id], apparent)
+		}
+	}
+	return freed
+}
+
+// Après la disparition de liens physiques, met à jour le nombre de liens de ceux qui restent dans l'arbre
+// Si le lien dont la taille était comptée a disparu, un lien restant la reprend à son compte
+func relinkRemaining(root *FileNode, removed []*FileNode) {
+	type gone struct {
+		links   uint64
+		counted bool
+	}
+	ids := make(map[fileID]*gone)
+	walkFiles(removed, func(n *FileNode) {
+		if n.Ino == 0 || n.Nlink <= 1 {
+			return
+		}
+		id := fileID{dev: n.Dev, ino: n.Ino}
+		if ids[id] == nil {
+			ids[id] = &gone{}
+		}
+		ids[id].links++
+		ids[id].counted = ids[id].counted || !n.Shared
+	})
+	if len(ids) == 0 {
+		return
+	}
+
+	walkFiles([]*FileNode{root}, func(n *FileNode) {
+		g := ids[fileID{dev: n.Dev, ino: n.Ino}]
+		if g == nil || n.Ino == 0 {
+			return
+		}
+		n.Nlink = max(n.Nlink-g.links, 1)
+		if g.counted && n.Shared {
+			n.Shared = false
+			g.counted = false
+			shrinkAncestors(n, -n.Size, -n.Apparent) // taille rajoutée aux dossiers parents
+		}
+	})
+}
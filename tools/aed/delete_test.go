@@ -0,0 +1,75 @@
+package aeddsa
+
+import "testing"
+
+// Deux liens physiques d'un même fichier, le second non compté comme après un scan
+func testLinks(a, b *FileNode, name string, size int64) (*FileNode, *FileNode) {
+	first := testFile(a, name, size)
+	second := testFile(b, name, size)
+	first.Dev, first.Ino, first.Nlink = 1, 42, 2
+	second.Dev, second.Ino, second.Nlink, second.Shared = 1, 42, 2, true
+	shrinkAncestors(second, size, size)
+	return first, second
+}
+
+func TestFreedSpace(t *testing.T) {
+	root := testRoot("/r")
+	a, b := testDir(root, "a"), testDir(root, "b")
+	first, second := testLinks(a, b, "lien", 1000)
+	plain := testFile(a, "seul", 10)
+
+	tests := []struct {
+		name  string
+		nodes []*FileNode
+		want  int64
+	}{
+		{"fichier simple", []*FileNode{plain}, 10},
+		{"un lien sur deux", []*FileNode{first}, 0},
+		{"lien non compté", []*FileNode{second}, 0},
+		{"tous les liens", []*FileNode{first, second}, 1000},
+		{"dossier et lien restant ailleurs", []*FileNode{a}, 10},
+		{"les deux dossiers", []*FileNode{a, b}, 1010},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := freedSpace(tt.nodes, false); got != tt.want {
+				t.Errorf("freedSpace = %d, attendu %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetachSharedLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		remove  func(first, second *FileNode) *FileNode
+		wantA   int64
+		wantB   int64
+		counted string // lien restant qui porte la taille
+	}{
+		{"lien non compté", func(_, second *FileNode) *FileNode { return second }, 1000, 0, "a"},
+		{"lien compté", func(first, _ *FileNode) *FileNode { return first }, 0, 1000, "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testRoot("/r")
+			a, b := testDir(root, "a"), testDir(root, "b")
+			first, second := testLinks(a, b, "lien", 1000)
+
+			n := tt.remove(first, second)
+			detachNode(n)
+			relinkRemaining(root, []*FileNode{n})
+
+			if a.Size != tt.wantA || b.Size != tt.wantB || root.Size != 1000 {
+				t.Errorf("tailles a=%d b=%d racine=%d, attendu %d, %d et 1000", a.Size, b.Size, root.Size, tt.wantA, tt.wantB)
+			}
+			left := first
+			if n == first {
+				left = second
+			}
+			if left.Shared || left.Nlink != 1 || left.Parent.Name != tt.counted {
+				t.Errorf("lien restant : partagé %v, %d lien(s)", left.Shared, left.Nlink)
+			}
+		})
+	}
+}
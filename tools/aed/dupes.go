@@ -0,0 +1,557 @@
+package aeddsa
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Taille lue pour le hash partiel (début du fichier)
+const partialHashSize = 4096
+
+// Groupe de fichiers au contenu identique
+type dupGroup struct {
+	size  int64 // taille apparente commune
+	files []*FileNode
+}
+
+// Espace gaspillé : toutes les copies sauf une
+func (g dupGroup) wasted() int64 {
+	if len(g.files) < 2 {
+		return 0
+	}
+	return g.files[0].Size * int64(len(g.files)-1)
+}
+
+// Ligne de la vue : en-tête de groupe (file = -1) ou fichier
+type dupRow struct {
+	group int
+	file  int
+}
+
+// Copie à remplacer par un lien physique vers la copie conservée
+type dupLink struct {
+	node   *FileNode
+	target *FileNode
+}
+
+// Progression de la recherche de doublons
+type dupProgress struct {
+	files atomic.Int64
+	bytes atomic.Int64
+}
+
+// Message envoyé lorsque la recherche de doublons est terminée
+type dupsFoundMsg struct {
+	progress *dupProgress // progression de la recherche, qui l'identifie
+	groups   []dupGroup
+	err      error
+}
+
+// Message envoyé lorsque les liens physiques ont été créés
+type linkDoneMsg struct {
+	linked []dupLink
+	errs   []error
+}
+
+// Commande Tea de recherche des doublons en arrière-plan
+func findDuplicatesCmd(ctx context.Context, root *FileNode, progress *dupProgress) tea.Cmd {
+	return func() tea.Msg {
+		groups, err := findDuplicates(ctx, root, progress)
+		return dupsFoundMsg{progress: progress, groups: groups, err: err}
+	}
+}
+
+// Regroupe les fichiers par taille, puis par hash partiel et enfin par hash complet
+// Les liens physiques d'un même inode ne sont pas des doublons et ne sont comptés qu'une fois
+func findDuplicates(ctx context.Context, root *FileNode, progress *dupProgress) ([]dupGroup, error) {
+	bySize := make(map[int64][]*FileNode)
+	seen := make(map[fileID]struct{})
+
+	var walk func(n *FileNode)
+	walk = func(n *FileNode) {
+		for _, child := range n.Children {
+			if child.IsDir {
+				walk(child)
+				continue
+			}
+			if child.Apparent == 0 {
+				continue
+			}
+			if child.Ino != 0 {
+				id := fileID{dev: child.Dev, ino: child.Ino}
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+			}
+			bySize[child.Apparent] = append(bySize[child.Apparent], child)
+		}
+	}
+	walk(root)
+
+	var candidates [][]*FileNode
+	for _, files := range bySize {
+		if len(files) > 1 {
+			candidates = append(candidates, files)
+		}
+	}
+
+	candidates, err := refineByHash(ctx, candidates, progress, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Le hash partiel couvre déjà les petits fichiers en entier
+	var small, large [][]*FileNode
+	for _, files := range candidates {
+		if files[0].Apparent <= partialHashSize {
+			small = append(small, files)
+		} else {
+			large = append(large, files)
+		}
+	}
+	large, err = refineByHash(ctx, large, progress, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []dupGroup
+	for _, files := range append(small, large...) {
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+		groups = append(groups, dupGroup{size: files[0].Apparent, files: files})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].wasted() != groups[j].wasted() {
+			return groups[i].wasted() > groups[j].wasted()
+		}
+		return groups[i].files[0].Path < groups[j].files[0].Path
+	})
+	return groups, nil
+}
+
+// Hache en parallèle les fichiers de chaque groupe et le découpe par hash identique
+// Les fichiers illisibles sont écartés
+func refineByHash(ctx context.Context, groups [][]*FileNode, progress *dupProgress, partial bool) ([][]*FileNode, error) {
+	hashes := make(map[*FileNode]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, scanWorkers)
+
+	for _, files := range groups {
+		for _, f := range files {
+			if ctx.Err() != nil {
+				break
+			}
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(f *FileNode) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				sum, err := hashFile(ctx, f.Path, partial, progress)
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				hashes[f] = sum
+				mu.Unlock()
+			}(f)
+		}
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var refined [][]*FileNode
+	for _, files := range groups {
+		byHash := make(map[string][]*FileNode)
+		var order []string
+		for _, f := range files {
+			sum, ok := hashes[f]
+			if !ok {
+				continue
+			}
+			if _, exists := byHash[sum]; !exists {
+				order = append(order, sum)
+			}
+			byHash[sum] = append(byHash[sum], f)
+		}
+		for _, sum := range order {
+			if len(byHash[sum]) > 1 {
+				refined = append(refined, byHash[sum])
+			}
+		}
+	}
+	return refined, nil
+}
+
+// Hash SHA-256 du début du fichier (partiel) ou du fichier entier
+func hashFile(ctx context.Context, path string, partial bool, progress *dupProgress) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if partial {
+		r = io.LimitReader(f, partialHashSize)
+	}
+
+	h := sha256.New()
+	buf := make([]byte, 256*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := r.Read(buf)
+		h.Write(buf[:n])
+		progress.bytes.Add(int64(n))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	progress.files.Add(1)
+	return string(h.Sum(nil)), nil
+}
+
+// Lance la recherche de doublons sur l'arbre complet
+func (m Model) startDuplicates() (Model, tea.Cmd) {
+	m.cancelDuplicates()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.dupCancel = cancel
+	m.dupProgress = &dupProgress{}
+	m.dups = nil
+	m.dupRows = nil
+	m.panel.reset()
+	m.state = StateDuplicates
+
+	return m, tea.Batch(m.spinner.Tick, findDuplicatesCmd(ctx, m.root, m.dupProgress))
+}
+
+// Affiche les doublons trouvés, ignorés s'ils concernent une recherche précédente
+func (m Model) applyDuplicates(msg dupsFoundMsg) Model {
+	if msg.progress != m.dupProgress {
+		return m
+	}
+	m.cancelDuplicates()
+	if msg.err != nil {
+		if m.state == StateDuplicates {
+			m.state = StateBrowsing
+		}
+		return m
+	}
+	m.dups = msg.groups
+	m.buildDupRows()
+	return m
+}
+
+func (m *Model) cancelDuplicates() {
+	if m.dupCancel != nil {
+		m.dupCancel()
+		m.dupCancel = nil
+	}
+}
+
+// Recalcule les lignes affichées à partir des groupes
+func (m *Model) buildDupRows() {
+	m.dupRows = nil
+	for gi, g := range m.dups {
+		m.dupRows = append(m.dupRows, dupRow{group: gi, file: -1})
+		for fi := range g.files {
+			m.dupRows = append(m.dupRows, dupRow{group: gi, file: fi})
+		}
+	}
+	if m.panel.cursor >= len(m.dupRows) {
+		m.panel.move(len(m.dupRows), len(m.dupRows), m.height-8)
+	}
+}
+
+// Retire des groupes les fichiers supprimés ou devenus des liens physiques, et les groupes sans doublon
+func (m *Model) pruneDuplicates(gone map[*FileNode]bool) {
+	var groups []dupGroup
+	for _, g := range m.dups {
+		var files []*FileNode
+		for _, f := range g.files {
+			if !gone[f] && !hasAncestor(f, gone) {
+				files = append(files, f)
+			}
+		}
+		if len(files) > 1 {
+			groups = append(groups, dupGroup{size: g.size, files: files})
+		}
+	}
+	m.dups = groups
+	m.buildDupRows()
+}
+
+func hasAncestor(n *FileNode, set map[*FileNode]bool) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if set[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// Marque toutes les copies sauf la première de chaque groupe
+func (m Model) markAllCopies() Model {
+	for _, g := range m.dups {
+		for i, f := range g.files {
+			if i == 0 {
+				delete(m.marked, f.Path)
+			} else {
+				m.marked[f.Path] = f
+			}
+		}
+	}
+	return m
+}
+
+// Prépare le remplacement des copies marquées par des liens vers la première copie non marquée du groupe
+func (m Model) openLinkConfirm() Model {
+	m.pendingLinks = nil
+	for _, g := range m.dups {
+		var target *FileNode
+		for _, f := range g.files {
+			if _, ok := m.marked[f.Path]; !ok {
+				target = f
+				break
+			}
+		}
+		if target == nil {
+			continue
+		}
+		for _, f := range g.files {
+			if _, ok := m.marked[f.Path]; ok {
+				m.pendingLinks = append(m.pendingLinks, dupLink{node: f, target: target})
+			}
+		}
+	}
+	if len(m.pendingLinks) == 0 {
+		m.status = errorStyle.Render("Aucune copie marquée avec une copie conservée dans son groupe")
+		return m
+	}
+	m.panel.reset()
+	m.state = StateConfirmLink
+	return m
+}
+
+// Vérifie qu'un fichier n'a changé ni de taille ni de date de modification depuis la recherche
+func unchangedSinceScan(n *FileNode) error {
+	info, err := os.Lstat(n.Path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() || info.Size() != n.Apparent || !info.ModTime().Equal(n.ModTime) {
+		return fmt.Errorf("%s : modifié depuis la recherche de doublons, ignoré", n.Path)
+	}
+	return nil
+}
+
+// La copie remplacée prendra les droits et le propriétaire de la copie conservée
+func sharesTargetMetadata(l dupLink) bool {
+	return l.node.Mode == l.target.Mode && l.node.Uid == l.target.Uid && l.node.Gid == l.target.Gid
+}
+
+// Commande Tea de remplacement des copies par des liens physiques (lien temporaire puis renommage atomique)
+// Une copie ou une cible modifiée depuis la recherche n'est pas remplacée : son contenu pourrait différer
+func linkCmd(links []dupLink) tea.Cmd {
+	return func() tea.Msg {
+		var msg linkDoneMsg
+		for _, l := range links {
+			if err := unchangedSinceScan(l.node); err != nil {
+				msg.errs = append(msg.errs, err)
+				continue
+			}
+			if err := unchangedSinceScan(l.target); err != nil {
+				msg.errs = append(msg.errs, err)
+				continue
+			}
+			tmp := filepath.Join(filepath.Dir(l.node.Path), fmt.Sprintf(".%s.aed-link", l.node.Name))
+			if err := os.Link(l.target.Path, tmp); err != nil {
+				msg.errs = append(msg.errs, err)
+				continue
+			}
+			if err := os.Rename(tmp, l.node.Path); err != nil {
+				os.Remove(tmp)
+				msg.errs = append(msg.errs, err)
+				continue
+			}
+			msg.linked = append(msg.linked, l)
+		}
+		return msg
+	}
+}
+
+// Répercute les liens créés : l'espace des copies est libéré mais les fichiers restent dans l'arbre
+func (m Model) applyLinks(msg linkDoneMsg) Model {
+	var freed int64
+	gone := make(map[*FileNode]bool)
+	for _, l := range msg.linked {
+		freed += nodeSize(l.node, m.apparent)
+		size, apparent := countedSize(l.node)
+		shrinkAncestors(l.node, size, apparent)
+		// La copie devient un lien de la cible, dont la taille est déjà comptée
+		l.node.Dev, l.node.Ino, l.node.Shared = l.target.Dev, l.target.Ino, true
+		l.target.Nlink++
+		l.node.Nlink = l.target.Nlink
+		delete(m.marked, l.node.Path)
+		gone[l.node] = true
+	}
+	m.pruneDuplicates(gone)
+
+	m.status = fmt.Sprintf("%d copie(s) remplacée(s) par un lien physique, %s libérés", len(msg.linked), formatBytes(freed))
+	if len(msg.errs) > 0 {
+		var errs []string
+		for _, err := range msg.errs {
+			errs = append(errs, err.Error())
+		}
+		m.status += "\n  " + errorStyle.Render(fmt.Sprintf("%d échec(s) : %s", len(msg.errs), strings.Join(errs, " ; ")))
+	}
+	m.pendingLinks = nil
+	return m.refreshAgeFilter()
+}
+
+func (m Model) updateDuplicates(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.dupCancel != nil {
+		if msg.String() == "esc" || msg.String() == "q" {
+			m.cancelDuplicates()
+			m.state = StateBrowsing
+		}
+		return m, nil
+	}
+
+	m.status = ""
+	switch msg.String() {
+	case "esc", "q", "D":
+		m.state = StateBrowsing
+		return m, nil
+	case " ":
+		if m.panel.cursor < len(m.dupRows) {
+			row := m.dupRows[m.panel.cursor]
+			if row.file >= 0 {
+				f := m.dups[row.group].files[row.file]
+				if _, ok := m.marked[f.Path]; ok {
+					delete(m.marked, f.Path)
+				} else {
+					m.marked[f.Path] = f
+				}
+			}
+			m.panel.move(1, len(m.dupRows), m.height-8)
+		}
+		return m, nil
+	case "a":
+		return m.markAllCopies(), nil
+	case "d", "delete":
+		if len(m.marked) == 0 {
+			m.status = errorStyle.Render("Aucune copie marquée")
+			return m, nil
+		}
+		return m.openDeleteConfirm(), nil
+	case "L":
+		return m.openLinkConfirm(), nil
+	}
+	m.panel.handleKey(msg.String(), len(m.dupRows), m.height-8)
+	return m, nil
+}
+
+func (m Model) viewDuplicates() string {
+	title := titleStyle.Render("AED - Doublons")
+
+	if m.dupCancel != nil {
+		return fmt.Sprintf("\n  %s\n\n  %s Recherche des doublons...\n\n%s fichiers hachés\n%s lus\n\n  %s",
+			title, m.spinner.View(),
+			countStyle.Render(fmt.Sprintf("%d", m.dupProgress.files.Load())),
+			countStyle.Render(formatBytes(m.dupProgress.bytes.Load())),
+			helpStyle.Render("(q/esc: annuler)"))
+	}
+
+	var wasted int64
+	for _, g := range m.dups {
+		wasted += g.wasted()
+	}
+	header := fmt.Sprintf("  %s  %s\n", title, infoStyle.Render(fmt.Sprintf("%d groupe(s), %s gaspillés", len(m.dups), formatBytes(wasted))))
+
+	var content string
+	if len(m.dups) == 0 {
+		content = "  Aucun doublon trouvé."
+	} else {
+		rows := make([]string, len(m.dupRows))
+		for i, r := range m.dupRows {
+			g := m.dups[r.group]
+			if r.file < 0 {
+				rows[i] = infoStyle.Render(fmt.Sprintf("%d copies × %s  (%s gaspillés)", len(g.files), formatBytes(g.size), formatBytes(g.wasted())))
+				continue
+			}
+			f := g.files[r.file]
+			mark := " "
+			if _, ok := m.marked[f.Path]; ok {
+				mark = markStyle.Render("*")
+			}
+			rows[i] = fmt.Sprintf("  %s %s", mark, f.Path)
+		}
+		content = m.panel.render(rows, m.height-8, m.width)
+	}
+
+	footer := helpStyle.Render("\nespace: marquer • a: marquer toutes les copies sauf une • d: supprimer • L: remplacer par des liens physiques • esc: retour")
+	if m.status != "" {
+		footer += "\n  " + m.status
+	}
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
+
+func (m Model) updateConfirmLink(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "n":
+		m.pendingLinks = nil
+		m.state = StateDuplicates
+		return m, nil
+	case "enter", "y":
+		m.state = StateDuplicates
+		m.status = "Création des liens..."
+		return m, linkCmd(m.pendingLinks)
+	}
+	m.panel.handleKey(msg.String(), len(m.pendingLinks), m.height-9)
+	return m, nil
+}
+
+func (m Model) viewConfirmLink() string {
+	var total int64
+	differing := 0
+	rows := make([]string, len(m.pendingLinks))
+	for i, l := range m.pendingLinks {
+		total += l.node.Size
+		rows[i] = fmt.Sprintf("%s %s %s", l.node.Path, dimStyle.Render("→"), l.target.Path)
+		if !sharesTargetMetadata(l) {
+			differing++
+			rows[i] += errorStyle.Render(fmt.Sprintf("  droits %s → %s", modeString(l.node.Mode), modeString(l.target.Mode)))
+			if l.node.Uid != l.target.Uid || l.node.Gid != l.target.Gid {
+				rows[i] += errorStyle.Render(fmt.Sprintf(", propriétaire %s → %s", userName(l.node.Uid), userName(l.target.Uid)))
+			}
+		}
+	}
+
+	title := titleStyle.Render("AED - Liens physiques")
+	header := fmt.Sprintf("  %s  %s\n", title, infoStyle.Render(fmt.Sprintf("%d copie(s), %s libérés", len(m.pendingLinks), formatBytes(total))))
+	content := m.panel.render(rows, m.height-9, m.width)
+	warning := dimStyle.Render("  Une copie liée partage l'inode de la cible : mêmes droits, propriétaire et contenu pour toutes les copies.")
+	if differing > 0 {
+		warning = errorStyle.Render(fmt.Sprintf("  %d copie(s) prendront les droits ou le propriétaire de la cible.", differing)) + "\n" + warning
+	}
+	footer := helpStyle.Render("\nenter: remplacer par des liens • esc: annuler")
+	return fmt.Sprintf("\n%s\n%s\n\n%s%s", header, content, warning, footer)
+}
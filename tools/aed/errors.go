@@ -0,0 +1,118 @@
+package aeddsa
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Cause d'une erreur de lecture, sans le chemin déjà connu du nœud
+func scanErrorMsg(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// Nombre d'éléments illisibles d'un sous-arbre, nœud compris
+func unreadable(n *FileNode) int64 {
+	if n.ReadError {
+		return n.Unreadable + 1
+	}
+	return n.Unreadable
+}
+
+// Mention ajoutée à un élément illisible ou à un dossier dont le total est incomplet
+func errorTag(n *FileNode) string {
+	switch {
+	case n.ReadError:
+		return errorStyle.Render(" ! illisible")
+	case n.Unreadable > 0:
+		return errorStyle.Render(fmt.Sprintf(" ! incomplet (%d)", n.Unreadable))
+	}
+	return ""
+}
+
+// Éléments illisibles de l'arbre, triés par chemin
+func unreadableNodes(root *FileNode) []*FileNode {
+	var nodes []*FileNode
+	var walk func(n *FileNode)
+	walk = func(n *FileNode) {
+		if n.ReadError {
+			nodes = append(nodes, n)
+		}
+		if n.Unreadable == 0 || !n.IsDir {
+			return
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Path < nodes[j].Path })
+	return nodes
+}
+
+// Ouvre la liste des éléments illisibles de tout l'arbre
+func (m Model) openErrors() Model {
+	m.errorNodes = unreadableNodes(m.root)
+	m.panel.reset()
+	m.state = StateErrors
+	return m
+}
+
+// Navigation dans la liste des erreurs ; enter montre l'élément dans son dossier
+func (m Model) updateErrors(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "!", "backspace":
+		m.state = StateBrowsing
+		return m, nil
+	case "enter":
+		if m.panel.cursor < len(m.errorNodes) {
+			n := m.errorNodes[m.panel.cursor]
+			if n.Parent == nil {
+				m.currentNode, m.cursor, m.yOffset = n, 0, 0
+				m.state = StateBrowsing
+				return m, nil
+			}
+			return m.revealNode(n), nil
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(m.errorNodes), m.height-7)
+	return m, nil
+}
+
+// Vue listant les éléments que le scan n'a pas pu lire et la cause de chaque erreur
+func (m Model) viewErrors() string {
+	title := titleStyle.Render("AED - Erreurs de lecture")
+	header := fmt.Sprintf("  %s  %s\n", title, infoStyle.Render(fmt.Sprintf("%d élément(s) illisible(s)", len(m.errorNodes))))
+
+	var content string
+	if len(m.errorNodes) == 0 {
+		content = "  Aucune erreur : les totaux sont complets."
+	} else {
+		rows := make([]string, len(m.errorNodes))
+		for i, n := range m.errorNodes {
+			cause := n.ErrorMsg
+			if cause == "" {
+				cause = "erreur de lecture"
+			}
+			name := n.Path
+			if n.IsDir {
+				name += string(filepath.Separator)
+			}
+			rows[i] = fmt.Sprintf("%s  %s", dimStyle.Render(fmt.Sprintf("%-28.28s", cause)), name)
+		}
+		content = m.panel.render(rows, m.height-7, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • enter: aller à l'élément • esc: retour")
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
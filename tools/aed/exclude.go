@@ -0,0 +1,296 @@
+package aeddsa
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Options du scan : exclusions utilisateur et limites de systèmes de fichiers
+type ScanOptions struct {
+	// Motifs glob (sur le nom, ou sur le chemin complet s'ils contiennent un /) ou regex préfixées par "re:"
+	Excludes []string
+	// Ne pas traverser les points de montage d'un autre système de fichiers que la racine
+	OneFileSystem bool
+	// Ignorer les pseudo-systèmes de fichiers du noyau (proc, sysfs, devtmpfs...) détectés via /proc/self/mountinfo
+	SkipPseudo bool
+	// Ignorer aussi les montages en mémoire (tmpfs) et réseau, qui peuvent contenir de vraies données
+	SkipVirtual bool
+}
+
+// Options par défaut de l'interface : seuls les pseudo-systèmes du noyau sont ignorés
+func DefaultScanOptions() ScanOptions {
+	return ScanOptions{SkipPseudo: true}
+}
+
+// Élément ignoré pendant le scan, nature et raison affichée de l'exclusion
+type Exclusion struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// Nature d'une exclusion (champ Kind)
+const (
+	excludePattern  = "pattern"  // motif ou regex utilisateur
+	excludePseudo   = "pseudo"   // pseudo-système de fichiers du noyau
+	excludeMemory   = "memory"   // montage en mémoire (tmpfs)
+	excludeNetwork  = "network"  // montage réseau
+	excludeOtherFS  = "otherfs"  // autre système de fichiers que la racine
+	excludeFirmlink = "firmlink" // firmlink macOS, connu seulement par un import ncdu
+)
+
+// Pseudo-systèmes de fichiers exposés par le noyau, sans données utilisateur
+var pseudoFSTypes = map[string]bool{
+	"proc": true, "sysfs": true, "devtmpfs": true, "devpts": true,
+	"cgroup": true, "cgroup2": true, "securityfs": true, "debugfs": true, "tracefs": true,
+	"pstore": true, "bpf": true, "mqueue": true, "hugetlbfs": true, "configfs": true,
+	"fusectl": true, "autofs": true, "binfmt_misc": true, "efivarfs": true,
+	"rpc_pipefs": true, "nsfs": true, "selinuxfs": true,
+}
+
+// Systèmes de fichiers en mémoire
+var memoryFSTypes = map[string]bool{"tmpfs": true, "ramfs": true}
+
+// Types de systèmes de fichiers réseau
+var networkFSTypes = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb3": true, "smbfs": true, "9p": true,
+	"afs": true, "ceph": true, "lustre": true, "fuse.sshfs": true, "fuse.glusterfs": true,
+	"fuse.davfs2": true, "fuse.rclone": true, "fuse.s3fs": true,
+}
+
+// Exclusions historiques appliquées à la racine lorsque /proc/self/mountinfo est indisponible
+var legacyRootExcludes = []string{"proc", "sys", "dev", "run"}
+
+// Règle d'exclusion compilée
+type excludeRule struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// Règles d'exclusion et informations de montage préparées avant le scan
+type excluder struct {
+	rules    []excludeRule
+	opts     ScanOptions
+	rootDev  uint64
+	mounts   map[string]string // point de montage -> type de système de fichiers
+	fallback bool              // mountinfo illisible : exclusions historiques
+}
+
+// Compile les motifs et lit la table des montages
+func newExcluder(opts ScanOptions, rootDev uint64) (*excluder, error) {
+	e := &excluder{opts: opts, rootDev: rootDev}
+
+	for _, p := range opts.Excludes {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		r, err := compileRule(p)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, r)
+	}
+
+	if opts.SkipPseudo || opts.SkipVirtual {
+		mounts, err := readMountInfo("/proc/self/mountinfo")
+		if err != nil {
+			e.fallback = true
+		}
+		e.mounts = mounts
+	}
+
+	return e, nil
+}
+
+// Compile un motif glob (sur le nom, ou sur le chemin complet s'il contient un /) ou une regex préfixée par "re:"
+func compileRule(p string) (excludeRule, error) {
+	if expr, ok := strings.CutPrefix(p, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return excludeRule{}, fmt.Errorf("regex invalide %q: %w", expr, err)
+		}
+		return excludeRule{pattern: p, re: re}, nil
+	}
+	if _, err := filepath.Match(p, ""); err != nil {
+		return excludeRule{}, fmt.Errorf("motif invalide %q: %w", p, err)
+	}
+	return excludeRule{pattern: p}, nil
+}
+
+// Indique si le chemin (ou le nom) correspond au motif
+func (r excludeRule) match(path, name string) bool {
+	if r.re != nil {
+		return r.re.MatchString(path)
+	}
+	target := name
+	if strings.Contains(r.pattern, "/") {
+		target = path
+	}
+	ok, _ := filepath.Match(r.pattern, target)
+	return ok
+}
+
+// Indique si l'entrée doit être ignorée, et pourquoi (exclusion renvoyée avec son chemin)
+// dev n'est utilisé que pour les dossiers (changement de système de fichiers)
+func (e *excluder) match(path, name string, isDir bool, dev uint64) (Exclusion, bool) {
+	excl := func(kind, reason string) (Exclusion, bool) {
+		return Exclusion{Path: path, Kind: kind, Reason: reason}, true
+	}
+
+	for _, r := range e.rules {
+		if !r.match(path, name) {
+			continue
+		}
+		if r.re != nil {
+			return excl(excludePattern, "regex "+r.pattern)
+		}
+		return excl(excludePattern, "motif "+r.pattern)
+	}
+
+	if !isDir {
+		return Exclusion{}, false
+	}
+
+	if fstype, ok := e.mounts[path]; ok {
+		switch {
+		case e.opts.SkipPseudo && pseudoFSTypes[fstype]:
+			return excl(excludePseudo, "pseudo-système ("+fstype+")")
+		case e.opts.SkipVirtual && memoryFSTypes[fstype]:
+			return excl(excludeMemory, "montage en mémoire ("+fstype+")")
+		case e.opts.SkipVirtual && networkFSTypes[fstype]:
+			return excl(excludeNetwork, "montage réseau ("+fstype+")")
+		}
+	}
+	if e.opts.SkipPseudo {
+		if e.fallback && filepath.Dir(path) == "/" {
+			for _, n := range legacyRootExcludes {
+				if name == n {
+					return excl(excludePseudo, "pseudo-système de fichiers")
+				}
+			}
+		}
+	}
+
+	if e.opts.OneFileSystem && dev != e.rootDev {
+		return excl(excludeOtherFS, "autre système de fichiers")
+	}
+
+	return Exclusion{}, false
+}
+
+// Points de montage ignorés, tels qu'affichés dans l'en-tête de l'explorateur
+func skippedMounts(exclusions []Exclusion) []string {
+	var paths []string
+	for _, e := range exclusions {
+		if e.Kind == excludePseudo || e.Kind == excludeMemory || e.Kind == excludeNetwork {
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths
+}
+
+// Résumé des montages ignorés pour l'en-tête (les premiers chemins, puis leur nombre)
+func mountsLabel(mounts []string) string {
+	const shown = 3
+	if len(mounts) == 0 {
+		return ""
+	}
+	if len(mounts) <= shown {
+		return "montages ignorés : " + strings.Join(mounts, ", ")
+	}
+	return fmt.Sprintf("montages ignorés : %s +%d (x)", strings.Join(mounts[:shown], ", "), len(mounts)-shown)
+}
+
+// Lit /proc/self/mountinfo et renvoie le type de système de fichiers de chaque point de montage
+func readMountInfo(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mounts := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// Format : id parent maj:min racine point_de_montage options [champs optionnels] - type source options
+		fields := strings.Fields(sc.Text())
+		sep := -1
+		for i, field := range fields {
+			if field == "-" {
+				sep = i
+				break
+			}
+		}
+		if len(fields) < 5 || sep < 0 || sep+1 >= len(fields) {
+			continue
+		}
+		mounts[unescapeMount(fields[4])] = fields[sep+1]
+	}
+	return mounts, sc.Err()
+}
+
+// Décode les séquences octales (\040 pour l'espace) des chemins de mountinfo
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Navigation dans la liste des exclusions
+func (m Model) updateExclusions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "x", "backspace":
+		m.state = StateBrowsing
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(m.exclusions), m.height-7)
+	return m, nil
+}
+
+// Vue listant les éléments ignorés pendant le scan et la raison de chaque exclusion
+func (m Model) viewExclusions() string {
+	title := titleStyle.Render("AED - Exclusions")
+	header := fmt.Sprintf("  %s  %s\n", title, infoStyle.Render(fmt.Sprintf("%d élément(s) ignoré(s)", len(m.exclusions))))
+
+	var content string
+	if len(m.exclusions) == 0 {
+		content = "  Aucun élément exclu."
+	} else {
+		rows := make([]string, len(m.exclusions))
+		for i, e := range m.exclusions {
+			rows[i] = fmt.Sprintf("%s  %s", dimStyle.Render(fmt.Sprintf("%-28s", e.Reason)), e.Path)
+		}
+		content = m.panel.render(rows, m.height-7, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • esc: retour")
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
+
+// Case à cocher pour les options de la vue de saisie
+func checkbox(on bool) string {
+	if on {
+		return "[x]"
+	}
+	return "[ ]"
+}
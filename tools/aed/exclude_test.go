@@ -0,0 +1,78 @@
+package aeddsa
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCompileRule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+		wantErr bool
+	}{
+		{"node_modules", "/src/app/node_modules", true, false},
+		{"*.log", "/var/log/syslog.log", true, false},
+		{"*.log", "/var/log/syslog", false, false},
+		// Sans /, le motif porte sur le nom seul
+		{"log", "/var/log/syslog", false, false},
+		{"/var/*/cache", "/var/lib/cache", true, false},
+		{"/var/*/cache", "/var/lib/apt/cache", false, false},
+		{`re:\.cache$`, "/home/u/.cache", true, false},
+		{`re:^/tmp/`, "/var/tmp/x", false, false},
+		{"re:(", "", false, true},
+		{"[", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			r, err := compileRule(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compileRule(%q) erreur = %v", tt.pattern, err)
+			}
+			if err != nil {
+				return
+			}
+			if got := r.match(tt.path, filepath.Base(tt.path)); got != tt.want {
+				t.Errorf("match(%q) = %v, attendu %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcluderMounts(t *testing.T) {
+	mounts := map[string]string{"/proc": "proc", "/tmp": "tmpfs", "/mnt/nas": "nfs4", "/home": "ext4"}
+	tests := []struct {
+		name string
+		opts ScanOptions
+		path string
+		want string
+	}{
+		{"pseudo par défaut", DefaultScanOptions(), "/proc", "pseudo-système (proc)"},
+		{"tmpfs scanné par défaut", DefaultScanOptions(), "/tmp", ""},
+		{"réseau scanné par défaut", DefaultScanOptions(), "/mnt/nas", ""},
+		{"tmpfs ignoré", ScanOptions{SkipVirtual: true}, "/tmp", "montage en mémoire (tmpfs)"},
+		{"réseau ignoré", ScanOptions{SkipVirtual: true}, "/mnt/nas", "montage réseau (nfs4)"},
+		{"pseudo inclus", ScanOptions{}, "/proc", ""},
+		{"disque", ScanOptions{SkipPseudo: true, SkipVirtual: true}, "/home", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &excluder{opts: tt.opts, mounts: mounts}
+			excl, _ := e.match(tt.path, filepath.Base(tt.path), true, 0)
+			if got := excl.Reason; got != tt.want {
+				t.Errorf("match(%q) = %q, attendu %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMountsLabel(t *testing.T) {
+	if got := mountsLabel(nil); got != "" {
+		t.Errorf("sans montage : %q", got)
+	}
+	got := mountsLabel([]string{"/proc", "/sys", "/dev", "/run"})
+	if want := "montages ignorés : /proc, /sys, /dev +1 (x)"; got != want {
+		t.Errorf("mountsLabel = %q, attendu %q", got, want)
+	}
+}
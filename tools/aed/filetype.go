@@ -0,0 +1,72 @@
+package aeddsa
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Catégories de fichiers, dans l'ordre d'affichage des légendes
+const (
+	kindDir      = "Dossier"
+	kindImage    = "Image"
+	kindVideo    = "Vidéo"
+	kindAudio    = "Audio"
+	kindArchive  = "Archive"
+	kindDocument = "Document"
+	kindCode     = "Code"
+	kindDisk     = "Image disque"
+	kindOther    = "Autre"
+)
+
+var fileKinds = []string{kindDir, kindImage, kindVideo, kindAudio, kindArchive, kindDocument, kindCode, kindDisk, kindOther}
+
+// Extensions de chaque catégorie ; une extension ne doit figurer que dans une seule liste
+var kindExts = []struct {
+	kind string
+	exts string
+}{
+	{kindImage, "jpg jpeg png gif bmp webp svg tif tiff heic raw cr2 nef ico psd xcf"},
+	{kindVideo, "mp4 mkv avi mov wmv flv webm m4v mpg mpeg"},
+	{kindAudio, "mp3 flac wav ogg opus m4a aac wma"},
+	{kindArchive, "zip tar gz tgz bz2 xz zst 7z rar deb rpm jar apk"},
+	{kindDocument, "pdf doc docx odt xls xlsx ods ppt pptx odp txt md rtf epub csv"},
+	{kindCode, "go c h cpp hpp rs py js ts java rb php sh json yaml yml toml xml html css sql"},
+	{kindDisk, "iso img qcow2 vmdk vdi vhd vhdx ova"},
+}
+
+// Catégorie associée à chaque extension connue
+var kindByExt = map[string]string{}
+
+func init() {
+	for _, k := range kindExts {
+		for _, ext := range strings.Fields(k.exts) {
+			kindByExt["."+ext] = k.kind
+		}
+	}
+}
+
+// Couleurs des catégories (treemap et légendes)
+var kindColors = map[string]lipgloss.Color{
+	kindDir:      "#00f6ff",
+	kindImage:    "#39FF14",
+	kindVideo:    "#FF2A6D",
+	kindAudio:    "#ff00d4",
+	kindArchive:  "#FFB800",
+	kindDocument: "#7B61FF",
+	kindCode:     "#00B3A4",
+	kindDisk:     "#FF6B00",
+	kindOther:    "#888888",
+}
+
+// Catégorie d'un nœud d'après son extension
+func fileKind(n *FileNode) string {
+	if n.IsDir {
+		return kindDir
+	}
+	if kind, ok := kindByExt[strings.ToLower(filepath.Ext(n.Name))]; ok {
+		return kind
+	}
+	return kindOther
+}
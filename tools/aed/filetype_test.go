@@ -0,0 +1,40 @@
+package aeddsa
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKindExtsUnique(t *testing.T) {
+	seen := make(map[string]string)
+	for _, k := range kindExts {
+		for _, ext := range strings.Fields(k.exts) {
+			if other, ok := seen[ext]; ok {
+				t.Errorf("extension %q dans %s et %s", ext, other, k.kind)
+			}
+			seen[ext] = k.kind
+		}
+	}
+}
+
+func TestFileKind(t *testing.T) {
+	tests := []struct {
+		name  string
+		isDir bool
+		want  string
+	}{
+		{"src", true, kindDir},
+		{"index.ts", false, kindCode},
+		{"film.MKV", false, kindVideo},
+		{"photo.jpeg", false, kindImage},
+		{"backup.tar", false, kindArchive},
+		{"debian.iso", false, kindDisk},
+		{"Makefile", false, kindOther},
+		{"archive.tar.gz", false, kindArchive},
+	}
+	for _, tt := range tests {
+		if got := fileKind(&FileNode{Name: tt.name, IsDir: tt.isDir}); got != tt.want {
+			t.Errorf("fileKind(%q) = %s, attendu %s", tt.name, got, tt.want)
+		}
+	}
+}
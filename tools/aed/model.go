@@ -1,38 +1,97 @@
 package aeddsa
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
 	"strings"
 	"sync/atomic"
-	"syscall"
+	"time"
 
+	"github.com/Quirky1869/cyberTools/tools"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Signal de retour au menu principal
-type BackMsg struct{}
-
-// Identifiant unique d'un fichier (device + inode) pour gérer les liens physiques
-type fileID struct {
-	dev uint64
-	ino uint64
+// Enregistrement de l'outil dans le menu principal
+func init() {
+	tools.Register(tools.Tool{
+		Name:        "AED",
+		Description: "Analyseur d'espace disque",
+		Category:    "Data",
+		New:         func(w, h int) tea.Model { return New(w, h) },
+		Command:     "aed",
+		Usage:       "<dossier|instantané|export ncdu>",
+		Open:        func(w, h int, arg string) (tea.Model, error) { return Open(w, h, arg) },
+
+		Headless:      func(args []string) int { return RunReport(args, os.Stdout, os.Stderr) },
+		HeadlessUsage: "[options] <dossier>",
+	})
 }
 
 // Structure représentant un nœud dans l'arborescence de fichiers
 type FileNode struct {
-	Name     string
-	Path     string
-	Size     int64
-	IsDir    bool
-	Children []*FileNode
-	Parent   *FileNode
+	Name         string
+	Path         string
+	Size         int64 // taille occupée sur le disque
+	Apparent     int64 // taille apparente (somme des tailles de fichiers pour un dossier)
+	IsDir        bool
+	ModTime      time.Time // date de modification de l'élément lui-même
+	AccessTime   time.Time // date du dernier accès à l'élément lui-même
+	NewestMod    time.Time // dossier : modification la plus récente de son contenu
+	NewestAccess time.Time // dossier : accès le plus récent à son contenu
+	Count        int64     // nombre d'éléments contenus, récursivement (dossiers)
+	Uid          uint32
+	Gid          uint32
+	Children     []*FileNode
+	Parent       *FileNode
+
+	// Métadonnées conservées pour l'export ncdu (liens physiques, droits, erreurs de lecture)
+	Dev       uint64
+	Ino       uint64
+	Nlink     uint64
+	Mode      uint32 // st_mode brut : type et droits
+	Shared    bool   // lien physique dont la taille est déjà comptée via un autre lien : absente de celle des dossiers
+	ReadError bool
+	ErrorMsg  string // cause de l'erreur de lecture (inconnue pour un import ncdu)
+
+	// Taille de l'entrée du dossier lui-même, non comptée dans Size/Apparent (export ncdu)
+	OwnSize     int64
+	OwnApparent int64
+
+	Unreadable int64 // éléments illisibles contenus, récursivement (dossiers)
+
+	Virtual bool // entrée d'archive, sans existence propre sur le disque
+}
+
+// Modification la plus récente : celle de l'élément, ou de tout son contenu pour un dossier
+func (n *FileNode) lastModified() time.Time {
+	if n.NewestMod.After(n.ModTime) {
+		return n.NewestMod
+	}
+	return n.ModTime
+}
+
+// Accès le plus récent : celui de l'élément, ou de tout son contenu pour un dossier
+func (n *FileNode) lastAccessed() time.Time {
+	if n.NewestAccess.After(n.AccessTime) {
+		return n.NewestAccess
+	}
+	return n.AccessTime
+}
+
+// Reporte sur un dossier les dates les plus récentes d'un de ses éléments
+func (n *FileNode) absorbTimes(child *FileNode) {
+	if t := child.lastModified(); t.After(n.NewestMod) {
+		n.NewestMod = t
+	}
+	if t := child.lastAccessed(); t.After(n.NewestAccess) {
+		n.NewestAccess = t
+	}
 }
 
 // Machine à états pour gérer les différentes vues de l'outil
@@ -42,12 +101,39 @@ const (
 	StateInputPath SessionState = iota
 	StateScanning
 	StateBrowsing
+	StateExclusions
+	StatePrompt
+	StateDiff
+	StateConfirmDelete
+	StateDuplicates
+	StateConfirmLink
+	StateTreemap
+	StateTypes
+	StateTypeFiles
+	StateAge
+	StateOwners
+	StateOwnerDirs
+	StateSearch
+	StateArchive
+	StateErrors
+	StateAudit
+	StateCompareScanning
+	StateCompare
+	StateSuggestions
+	StateSuggestionMatches
 )
 
+// Message de démarrage du scan (ouverture directe depuis la ligne de commande)
+type startScanMsg struct {
+	path string
+}
+
 // Message envoyé lorsque le scan récursif est terminé
 type scanFinishedMsg struct {
-	root *FileNode
-	err  error
+	root       *FileNode
+	exclusions []Exclusion
+	err        error
+	snapshot   time.Time // date de l'instantané, nulle pour un scan
 }
 
 // Définition des styles visuels pour Lipgloss
@@ -62,20 +148,138 @@ var (
 	barEmpty      = lipgloss.NewStyle().Foreground(lipgloss.Color("#444444"))
 
 	countStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00f6ff")).Bold(true).PaddingLeft(2)
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Bold(true)
+	dimStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+	markStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff00d4")).Bold(true)
 )
 
 // Modèle principal contenant l'état du scanner et de l'interface
 type Model struct {
-	state     SessionState
-	textInput textinput.Model
-	spinner   spinner.Model
-
-	root        *FileNode
-	currentNode *FileNode
-	cursor      int
-	yOffset     int
-
-	filesScanned *int64
+	state        SessionState
+	textInput    textinput.Model
+	excludeInput textinput.Model
+	inputFocus   int
+	spinner      spinner.Model
+	options      ScanOptions
+
+	root         *FileNode
+	currentNode  *FileNode
+	cursor       int
+	yOffset      int
+	fullHelp     bool      // liste complète des touches sous l'explorateur
+	scannedAt    time.Time // date du scan ou de l'instantané chargé
+	fromSnapshot bool
+	status       string
+
+	progress *scanProgress
+	cancel   context.CancelFunc
+
+	// Vues secondaires (liste partagée)
+	exclusions []Exclusion
+	mounts     []string // points de montage ignorés, rappelés dans l'en-tête
+	panel      listPanel
+
+	// Saisie ponctuelle et comparaison d'instantanés
+	prompt      prompt
+	deltas      []dirDelta
+	compareFrom time.Time
+	compareTo   time.Time
+
+	// Éléments marqués (par chemin) et en attente de confirmation de suppression
+	marked      map[string]*FileNode
+	pending     []*FileNode
+	returnState SessionState
+
+	// Recherche de doublons
+	dups         []dupGroup
+	dupRows      []dupRow
+	dupProgress  *dupProgress
+	dupCancel    context.CancelFunc
+	pendingLinks []dupLink
+
+	// Tri et colonnes optionnelles de l'explorateur
+	sortMode    sortMode
+	sortReverse bool
+	columns     columnSet
+	apparent    bool // tailles apparentes plutôt que l'occupation disque
+
+	// Treemap : rectangle sélectionné et coloration par âge plutôt que par type
+	tmCursor int
+	tmByAge  bool
+
+	// Répartition par type du sous-arbre : par extension immédiatement, par type MIME après analyse
+	typeRoot    *FileNode
+	typeFiles   []*FileNode
+	typeByExt   []typeStat
+	typeByMime  []typeStat
+	typeGroup   int
+	typeSel     int
+	typePanel   listPanel // position dans la liste des types pendant l'affichage des fichiers
+	typeSniffed *atomic.Int64
+	typeCancel  context.CancelFunc
+
+	// Filtre d'âge : fichiers modifiés il y a plus de N jours, cumulés par dossier
+	olderThan int
+	oldStats  map[string]usageStat
+
+	// Histogrammes d'âge du dossier affiché, calculés à l'ouverture de la vue
+	ageRoot  *FileNode
+	ageFiles int
+	ageStats [][]usageStat // une série par date (modification, accès)
+
+	// Occupation par utilisateur ou par groupe du sous-arbre
+	ownerRoot    *FileNode
+	ownerFiles   []*FileNode
+	ownerByGroup bool
+	owners       []ownerStat
+	ownerSel     int
+	ownerDirs    []ownerDir
+	ownerPanel   listPanel // position dans la liste des propriétaires pendant l'affichage des dossiers
+
+	// Recherche par nom, motif glob ou regex dans tout l'arbre
+	searchInput     textinput.Model
+	searchResults   []*FileNode
+	searchTruncated bool
+	searchErr       error
+
+	// Éléments illisibles relevés pendant le scan
+	errorNodes []*FileNode
+
+	// Audit des droits du sous-arbre : capacités lues en arrière-plan
+	auditRoot     *FileNode
+	auditFindings []auditFinding
+	auditExecs    []*FileNode
+	auditFilter   int // catégorie affichée, -1 pour toutes
+	auditRead     *atomic.Int64
+	auditCancel   context.CancelFunc
+
+	// Comparaison du dossier courant (gauche) avec un autre dossier scanné (droite)
+	cmpLeft         *FileNode
+	cmpRightPath    string
+	cmpProgress     *scanProgress
+	cmpCancel       context.CancelFunc
+	cmpRoot         *cmpNode
+	cmpCurrent      *cmpNode
+	cmpOnlyDiffs    bool
+	cmpHashProgress *dupProgress
+	cmpHashTotal    int
+	cmpHashCancel   context.CancelFunc
+
+	// Suggestions de nettoyage du sous-arbre, regroupées par règle
+	suggestRoot  *FileNode
+	suggestions  []suggestion
+	suggestSel   int
+	suggestPanel listPanel // position dans la liste des règles pendant l'affichage des éléments
+	suggestErr   error     // configuration invalide : seules les règles intégrées sont appliquées
+
+	// Lecture du contenu d'une archive
+	archiveLoading *FileNode
+	archiveRead    *atomic.Int64
+	archiveCancel  context.CancelFunc
+
+	// Surveillance inotify de l'arbre et date du dernier changement de chaque élément (par chemin)
+	watch   *watcher
+	changed map[string]time.Time
 
 	width, height int
 	err           error
@@ -90,23 +294,52 @@ func New(w, h int) Model {
 	ti.Width = 50
 	ti.SetValue(".")
 
+	// Motifs d'exclusion séparés par des virgules
+	ei := textinput.New()
+	ei.Placeholder = "node_modules, *.iso, re:\\.cache$"
+	ei.CharLimit = 512
+	ei.Width = 50
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF2A6D"))
 
-	zero := int64(0)
-
 	return Model{
 		state:        StateInputPath,
 		textInput:    ti,
+		excludeInput: ei,
+		prompt:       prompt{input: newPromptInput()},
+		searchInput:  newSearchInput(),
+		marked:       make(map[string]*FileNode),
 		spinner:      s,
-		filesScanned: &zero,
+		options:      DefaultScanOptions(),
+		progress:     newScanProgress(),
 		width:        w,
 		height:       h,
 	}
 }
 
+// Lance directement l'analyse du dossier donné ou le chargement d'un instantané/export ncdu (sans passer par la saisie du chemin)
+func Open(w, h int, path string) (Model, error) {
+	m := New(w, h)
+	info, err := os.Stat(path)
+	if err != nil {
+		return m, err
+	}
+	if !info.IsDir() && !info.Mode().IsRegular() {
+		return m, fmt.Errorf("%s n'est ni un dossier ni un instantané", path)
+	}
+	m.textInput.SetValue(path)
+	m.state = StateScanning
+	return m, nil
+}
+
 func (m Model) Init() tea.Cmd {
+	// Ouverture directe : le scan démarre immédiatement
+	if m.state == StateScanning {
+		path := m.textInput.Value()
+		return func() tea.Msg { return startScanMsg{path: path} }
+	}
 	return textinput.Blink
 }
 
@@ -119,10 +352,21 @@ func (m Model) getDisplayItems() []*FileNode {
 	}
 
 	dot := &FileNode{
-		Name:  ".",
-		Path:  m.currentNode.Path,
-		Size:  m.currentNode.Size,
-		IsDir: true,
+		Name:      ".",
+		Path:      m.currentNode.Path,
+		Size:      m.currentNode.Size,
+		Apparent:  m.currentNode.Apparent,
+		IsDir:     true,
+		ModTime:   m.currentNode.ModTime,
+		NewestMod: m.currentNode.NewestMod,
+		Count:     m.currentNode.Count,
+		Uid:       m.currentNode.Uid,
+		Virtual:   m.currentNode.Virtual,
+	}
+	// Archive ouverte : le total est celui de ses entrées
+	if !m.currentNode.IsDir {
+		dot.Size, dot.Apparent = archiveTotals(m.currentNode)
+		dot.Virtual = true
 	}
 	items = append(items, dot)
 
@@ -137,11 +381,59 @@ func (m Model) getDisplayItems() []*FileNode {
 		items = append(items, dotdot)
 	}
 
-	items = append(items, m.currentNode.Children...)
+	for _, child := range m.currentNode.Children {
+		if m.passesAgeFilter(child) {
+			items = append(items, child)
+		}
+	}
 
 	return items
 }
 
+// Démarre un nouveau scan annulable avec une progression remise à zéro
+func (m Model) startScan(path string) (Model, tea.Cmd) {
+	m.cancelScan()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.progress = newScanProgress()
+	m.state = StateScanning
+	m.err = nil
+
+	return m, tea.Batch(
+		m.spinner.Tick,
+		scanDirectoryCmd(ctx, path, m.options, m.progress),
+	)
+}
+
+// Charge un instantané ou un export ncdu à la place d'un scan
+func (m Model) startLoad(path string) (Model, tea.Cmd) {
+	m.cancelScan()
+	m.progress = newScanProgress()
+	m.state = StateScanning
+	m.err = nil
+
+	return m, tea.Batch(m.spinner.Tick, loadTreeCmd(path))
+}
+
+// Annule le scan en cours (sans effet s'il n'y en a pas)
+func (m *Model) cancelScan() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+}
+
+// Arrête tous les traitements en arrière-plan et la surveillance avant de quitter l'outil
+func (m *Model) cancelAll() {
+	m.cancelScan()
+	m.cancelDuplicates()
+	m.cancelTypes()
+	m.cancelAudit()
+	m.cancelTreeCompare()
+	m.cancelArchive()
+	m.stopWatch()
+}
+
 // Boucle principale de gestion des événements et des états
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -153,7 +445,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
-			return m, func() tea.Msg { return BackMsg{} }
+			m.cancelAll()
+			return m, tools.Back
 		}
 
 		// Gestion de la saisie du chemin à analyser
@@ -165,42 +458,229 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					path, _ = os.Getwd()
 				}
 
-				m.state = StateScanning
-				atomic.StoreInt64(m.filesScanned, 0)
-				visitedInodes := make(map[fileID]struct{})
+				if isTreeFile(path) {
+					return m.startLoad(path)
+				}
 
-				return m, tea.Batch(
-					m.spinner.Tick,
-					scanDirectoryCmd(path, m.filesScanned, visitedInodes),
-				)
-			case "esc", "q":
-				return m, func() tea.Msg { return BackMsg{} }
+				m.options.Excludes = strings.Split(m.excludeInput.Value(), ",")
+				return m.startScan(path)
+			case "esc":
+				m.cancelAll()
+				return m, tools.Back
+
+			// Bascule entre le chemin et les motifs d'exclusion
+			case "tab", "shift+tab", "up", "down":
+				m.inputFocus = 1 - m.inputFocus
+				if m.inputFocus == 0 {
+					m.excludeInput.Blur()
+					m.textInput.Focus()
+				} else {
+					m.textInput.Blur()
+					m.excludeInput.Focus()
+				}
+				return m, textinput.Blink
+
+			// Options de limites de systèmes de fichiers
+			case "ctrl+o":
+				m.options.OneFileSystem = !m.options.OneFileSystem
+				return m, nil
+			case "ctrl+p":
+				m.options.SkipPseudo = !m.options.SkipPseudo
+				return m, nil
+			case "ctrl+n":
+				m.options.SkipVirtual = !m.options.SkipVirtual
+				return m, nil
+			}
+			if m.inputFocus == 0 {
+				m.textInput, cmd = m.textInput.Update(msg)
+			} else {
+				m.excludeInput, cmd = m.excludeInput.Update(msg)
 			}
-			m.textInput, cmd = m.textInput.Update(msg)
 			return m, cmd
 		}
 
-		// Gestion pendant le scan (permet d'annuler)
+		// Liste des éléments exclus du scan
+		if m.state == StateExclusions {
+			return m.updateExclusions(msg)
+		}
+
+		// Saisie ponctuelle et comparaison avec un instantané
+		if m.state == StatePrompt {
+			return m.updatePrompt(msg)
+		}
+		if m.state == StateDiff {
+			return m.updateDiff(msg)
+		}
+		if m.state == StateConfirmDelete {
+			return m.updateConfirmDelete(msg)
+		}
+		if m.state == StateDuplicates {
+			return m.updateDuplicates(msg)
+		}
+		if m.state == StateConfirmLink {
+			return m.updateConfirmLink(msg)
+		}
+		if m.state == StateTreemap {
+			return m.updateTreemap(msg)
+		}
+		if m.state == StateTypes {
+			return m.updateTypes(msg)
+		}
+		if m.state == StateTypeFiles {
+			return m.updateTypeFiles(msg)
+		}
+		if m.state == StateAge {
+			return m.updateAge(msg)
+		}
+		if m.state == StateOwners {
+			return m.updateOwners(msg)
+		}
+		if m.state == StateOwnerDirs {
+			return m.updateOwnerDirs(msg)
+		}
+		if m.state == StateSearch {
+			return m.updateSearch(msg)
+		}
+		if m.state == StateArchive {
+			return m.updateArchive(msg)
+		}
+		if m.state == StateErrors {
+			return m.updateErrors(msg)
+		}
+		if m.state == StateAudit {
+			return m.updateAudit(msg)
+		}
+		if m.state == StateCompareScanning {
+			return m.updateCompareScanning(msg)
+		}
+		if m.state == StateCompare {
+			return m.updateCompare(msg)
+		}
+		if m.state == StateSuggestions {
+			return m.updateSuggestions(msg)
+		}
+		if m.state == StateSuggestionMatches {
+			return m.updateSuggestionMatches(msg)
+		}
+
+		// Gestion pendant le scan : l'annulation arrête réellement les workers
 		if m.state == StateScanning {
 			if msg.String() == "q" || msg.String() == "esc" {
-				return m, func() tea.Msg { return BackMsg{} }
+				m.cancelAll()
+				return m, tools.Back
 			}
 		}
 
 		// Gestion de la navigation dans les résultats
 		if m.state == StateBrowsing {
 			items := m.getDisplayItems()
+			m.status = ""
 
 			switch msg.String() {
 
 			case "q":
-				return m, func() tea.Msg { return BackMsg{} }
+				m.cancelAll()
+				return m, tools.Back
+
+			case "x":
+				m.state = StateExclusions
+				m.panel.reset()
+				return m, nil
+
+			// Éléments illisibles : totaux incomplets
+			case "!":
+				return m.openErrors(), nil
+
+			// Instantanés : enregistrement et comparaison
+			case "ctrl+s":
+				return m.saveCurrentSnapshot(), nil
+			case "c":
+				return m.openComparePrompt()
+
+			// Comparaison du dossier courant avec un autre dossier (vérification d'une copie)
+			case "C":
+				return m.openTreeComparePrompt()
+			case "e":
+				return m.openExportPrompt()
+
+			// Marquage et suppression
+			case " ":
+				return m.toggleMark(), nil
+			case "d", "delete":
+				return m.openDeleteConfirm(), nil
+			case "D":
+				return m.startDuplicates()
+
+			// Tri (o : mode suivant, r : ordre inverse) et colonnes optionnelles
+			case "o":
+				m.sortMode = (m.sortMode + 1) % sortModeCount
+				return m.resort(), nil
+			case "r":
+				m.sortReverse = !m.sortReverse
+				return m.resort(), nil
+			case "1":
+				m.columns.count = !m.columns.count
+				return m, nil
+			case "2":
+				m.columns.mtime = !m.columns.mtime
+				return m, nil
+			case "3":
+				m.columns.owner = !m.columns.owner
+				return m, nil
+			case "4":
+				m.columns.percent = !m.columns.percent
+				return m, nil
+
+			// Taille apparente ou occupation disque pour les totaux, barres et tri
+			case "a":
+				return m.toggleApparent(), nil
+
+			// Répartition du dossier courant par extension et par type MIME
+			case "t":
+				return m.startTypes()
+
+			// Liste complète des touches
+			case "?":
+				m.fullHelp = !m.fullHelp
+				return m, nil
+
+			// Histogramme d'âge du dossier courant et filtre des fichiers anciens
+			case "A":
+				return m.openAge(), nil
+			case "f":
+				return m.openAgeFilterPrompt()
+
+			// Recherche dans tout l'arbre
+			case "/":
+				return m.openSearch()
+
+			// Mise à jour en direct de l'arbre (inotify)
+			case "w":
+				return m.toggleWatch()
+
+			// Occupation du dossier courant par utilisateur et par groupe
+			case "u":
+				return m.openOwners(), nil
+
+			// Audit de sécurité : setuid, droits trop larges, propriétaires disparus, capacités
+			case "p":
+				return m.startAudit()
+
+			// Suggestions de nettoyage : caches, node_modules, core dumps, journaux archivés...
+			case "n":
+				return m.openSuggestions(), nil
+
+			// Visualisation en treemap du dossier courant
+			case "m":
+				m.state = StateTreemap
+				m.tmCursor = 0
+				return m, nil
 
 			// Ouvre le fichier ou dossier avec l'explorateur par défaut du système (xdg-open)
 			case "g":
 				if len(items) > 0 && m.cursor < len(items) {
 					selected := items[m.cursor]
-					cmd := exec.Command("xdg-open", selected.Path)
+					cmd := exec.Command("xdg-open", diskPath(selected))
 					cmd.Start()
 				}
 				return m, nil
@@ -210,9 +690,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if len(items) > 0 && m.cursor < len(items) {
 					selected := items[m.cursor]
 
-					targetPath := selected.Path
-					if !selected.IsDir {
-						targetPath = filepath.Dir(selected.Path)
+					targetPath := diskPath(selected)
+					if !selected.IsDir || selected.Virtual {
+						targetPath = filepath.Dir(targetPath)
 					}
 
 					shell := os.Getenv("SHELL")
@@ -252,6 +732,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if selected.Name == "." {
 						return m, nil
 					}
+					// Une archive s'ouvre comme un dossier virtuel
+					if !selected.IsDir && !selected.Virtual && archiveFormat(selected.Name) != "" {
+						return m.openArchive(selected)
+					}
 					if selected.IsDir {
 						for _, child := range m.currentNode.Children {
 							if child.Path == selected.Path {
@@ -275,7 +759,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "down", "j":
 				if m.cursor < len(items)-1 {
 					m.cursor++
-					visibleHeight := m.height - 7
+					visibleHeight := m.listHeight()
 					if m.cursor >= m.yOffset+visibleHeight {
 						m.yOffset = m.cursor - visibleHeight + 1
 					}
@@ -283,19 +767,79 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	// Souris : uniquement utilisée par la treemap
+	case tea.MouseMsg:
+		if m.state == StateTreemap {
+			return m.updateTreemap(msg)
+		}
+
+	case startScanMsg:
+		if isTreeFile(msg.path) {
+			return m.startLoad(msg.path)
+		}
+		return m.startScan(msg.path)
+
 	// Réception du résultat du scan
 	case scanFinishedMsg:
+		m.cancelScan()
+		m.stopWatch()
 		if msg.err != nil {
 			m.err = msg.err
 			m.state = StateInputPath
 		} else {
 			m.root = msg.root
 			m.currentNode = msg.root
+			m.exclusions = msg.exclusions
+			m.mounts = skippedMounts(msg.exclusions)
+			m.marked = make(map[string]*FileNode)
+			m.olderThan, m.oldStats = 0, nil
+			if m.sortMode != sortBySize || m.sortReverse || m.apparent {
+				sortTree(m.root, m.sortMode, m.sortReverse, m.sizeOf)
+			}
+			m.scannedAt = msg.snapshot
+			m.fromSnapshot = !msg.snapshot.IsZero()
+			if !m.fromSnapshot {
+				m.scannedAt = time.Now()
+			}
+			if n := unreadable(m.root); n > 0 {
+				m.status = errorStyle.Render(fmt.Sprintf("%d élément(s) illisible(s) : totaux incomplets (!: détail)", n))
+			}
 			m.state = StateBrowsing
 		}
 
+	case compareLoadedMsg:
+		return m.applyCompare(msg), nil
+
+	case deleteDoneMsg:
+		return m.applyDelete(msg), nil
+
+	case dupsFoundMsg:
+		return m.applyDuplicates(msg), nil
+
+	case linkDoneMsg:
+		return m.applyLinks(msg), nil
+
+	case mimeFoundMsg:
+		return m.applyMime(msg), nil
+
+	case capsFoundMsg:
+		return m.applyCapabilities(msg), nil
+
+	case cmpScannedMsg:
+		return m.applyCompareScan(msg), nil
+
+	case cmpHashedMsg:
+		return m.applyCompareHash(msg), nil
+
+	case archiveLoadedMsg:
+		return m.applyArchive(msg), nil
+
+	case watchReadyMsg, watchEventsMsg, watchScannedMsg, watchTickMsg:
+		return m.updateWatch(msg)
+
 	case spinner.TickMsg:
-		if m.state == StateScanning {
+		if m.state == StateScanning || m.dupCancel != nil || m.typeCancel != nil || m.archiveCancel != nil || m.auditCancel != nil ||
+			m.cmpCancel != nil || m.cmpHashCancel != nil {
 			var cmdSpinner tea.Cmd
 			m.spinner, cmdSpinner = m.spinner.Update(msg)
 			return m, cmdSpinner
@@ -310,16 +854,95 @@ func (m Model) View() string {
 	if m.state == StateInputPath {
 		title := titleStyle.Render("AED - Analyseur d'Espace Disque")
 		input := m.textInput.View()
-		return fmt.Sprintf("\n  %s\n\n  Entrez le dossier à analyser :\n  %s\n\n  %s", title, input, helpStyle.Render("(enter: valider • esc: quitter)"))
+		exclude := m.excludeInput.View()
+
+		options := fmt.Sprintf("  %s Rester sur le même système de fichiers (ctrl+o)\n  %s Ignorer les pseudo-systèmes du noyau : proc, sysfs, devtmpfs... (ctrl+p)\n  %s Ignorer les montages en mémoire (tmpfs) et réseau (ctrl+n)",
+			checkbox(m.options.OneFileSystem), checkbox(m.options.SkipPseudo), checkbox(m.options.SkipVirtual))
+
+		errLine := ""
+		if m.err != nil {
+			errLine = "\n\n  " + errorStyle.Render(m.err.Error())
+		}
+
+		return fmt.Sprintf("\n  %s\n\n  Entrez le dossier à analyser (ou un instantané .aedsnap / export ncdu) :\n  %s\n\n  Exclusions (motifs glob séparés par des virgules, re: pour une regex) :\n  %s\n\n%s%s\n\n  %s",
+			title, input, exclude, options, errLine, helpStyle.Render("(enter: valider • tab: champ suivant • esc: quitter)"))
+	}
+
+	if m.state == StateExclusions {
+		return m.viewExclusions()
+	}
+	if m.state == StatePrompt {
+		return m.viewPrompt()
+	}
+	if m.state == StateDiff {
+		return m.viewDiff()
+	}
+	if m.state == StateConfirmDelete {
+		return m.viewConfirmDelete()
+	}
+	if m.state == StateDuplicates {
+		return m.viewDuplicates()
+	}
+	if m.state == StateTypes {
+		return m.viewTypes()
+	}
+	if m.state == StateTypeFiles {
+		return m.viewTypeFiles()
+	}
+	if m.state == StateAge {
+		return m.viewAge()
+	}
+	if m.state == StateOwners {
+		return m.viewOwners()
+	}
+	if m.state == StateOwnerDirs {
+		return m.viewOwnerDirs()
+	}
+	if m.state == StateSearch {
+		return m.viewSearch()
+	}
+	if m.state == StateArchive {
+		return m.viewArchive()
+	}
+	if m.state == StateErrors {
+		return m.viewErrors()
+	}
+	if m.state == StateAudit {
+		return m.viewAudit()
+	}
+	if m.state == StateCompareScanning {
+		return m.viewCompareScanning()
+	}
+	if m.state == StateCompare {
+		return m.viewCompare()
+	}
+	if m.state == StateSuggestions {
+		return m.viewSuggestions()
+	}
+	if m.state == StateSuggestionMatches {
+		return m.viewSuggestionMatches()
+	}
+	if m.state == StateConfirmLink {
+		return m.viewConfirmLink()
+	}
+	if m.state == StateTreemap {
+		return m.viewTreemap()
 	}
 
-	// Vue 2 : Chargement (Spinner)
+	// Vue 2 : Progression du scan (fichiers, octets comptés, débit et dossier courant)
 	if m.state == StateScanning {
-		count := atomic.LoadInt64(m.filesScanned)
+		current, _ := m.progress.current.Load().(string)
+		if maxLen := m.width - 14; maxLen > 3 && len(current) > maxLen {
+			current = "..." + current[len(current)-maxLen+3:]
+		}
 		return fmt.Sprintf(
-			"\n  %s Analyse en cours...\n\n%s fichiers scannés",
+			"\n  %s Analyse en cours...\n\n%s fichiers scannés\n%s comptés (%s/s)\n\n  Dossier : %s\n\n  %s",
 			m.spinner.View(),
-			countStyle.Render(fmt.Sprintf("%d", count)),
+			countStyle.Render(fmt.Sprintf("%d", m.progress.files.Load())),
+			countStyle.Render(formatBytes(m.progress.bytes.Load())),
+			formatBytes(m.progress.throughput()),
+			pathStyle.Render(current),
+			helpStyle.Render("(q/esc: annuler)"),
 		)
 	}
 
@@ -329,21 +952,19 @@ func (m Model) View() string {
 			return "Erreur: Node vide"
 		}
 
-		title := titleStyle.Render("AED")
-		path := pathStyle.Render(m.currentNode.Path)
-		totalSize := infoStyle.Render(fmt.Sprintf("Total: %s", formatBytes(m.currentNode.Size)))
-
-		header := fmt.Sprintf("  %s  %s  (%s)\n", title, path, totalSize)
+		header := m.browserHeader()
 
 		var rows []string
 		items := m.getDisplayItems()
 
-		visibleHeight := m.height - 7
-		if visibleHeight < 1 {
-			visibleHeight = 1
-		}
+		visibleHeight := m.listHeight()
+		footer := m.browserFooter()
 
+		// La hauteur disponible peut avoir diminué depuis le dernier déplacement (statut, aide complète)
 		start := m.yOffset
+		if m.cursor >= start+visibleHeight {
+			start = m.cursor - visibleHeight + 1
+		}
 		end := start + visibleHeight
 		if end > len(items) {
 			end = len(items)
@@ -359,17 +980,17 @@ func (m Model) View() string {
 			if item.Name == "." || item.Name == ".." {
 				sizeStr = fmt.Sprintf("%8s", "")
 				if item.Name == "." {
-					sizeStr = fmt.Sprintf("%8s", formatBytes(item.Size))
+					sizeStr = fmt.Sprintf("%8s", formatBytes(m.sizeOf(item)))
 				}
 				bar = strings.Repeat(" ", barWidth)
 				name = item.Name
 			} else {
-				sizeStr = fmt.Sprintf("%8s", formatBytes(item.Size))
+				sizeStr = fmt.Sprintf("%8s", formatBytes(m.sizeOf(item)))
 
 				// Calcul du pourcentage pour la barre visuelle
 				percent := 0.0
-				if m.currentNode.Size > 0 {
-					percent = float64(item.Size) / float64(m.currentNode.Size)
+				if total := m.currentTotal(); total > 0 {
+					percent = float64(m.sizeOf(item)) / float64(total)
 				}
 				filledLen := int(percent * float64(barWidth))
 				emptyLen := barWidth - filledLen
@@ -379,9 +1000,21 @@ func (m Model) View() string {
 				if item.IsDir {
 					name += "/"
 				}
+				if m.recentlyChanged(item) {
+					name = changedStyle.Render(name)
+				}
+				name += m.archiveTag(item)
+				// Élément illisible pendant le scan ou dossier dont la taille est incomplète
+				name += errorTag(item)
+				name += m.sparseTag(item)
 			}
 
-			row := fmt.Sprintf("%s  %s  %s", sizeStr, bar, name)
+			mark := " "
+			if _, ok := m.marked[item.Path]; ok && item.Name != "." && item.Name != ".." {
+				mark = markStyle.Render("*")
+			}
+
+			row := fmt.Sprintf("%s%s  %s  %s%s", mark, sizeStr, bar, m.columnsFor(item), name)
 
 			if i == m.cursor {
 				row = selectedStyle.Render(fmt.Sprintf("%-*s", m.width-4, row))
@@ -392,106 +1025,80 @@ func (m Model) View() string {
 		}
 
 		content := strings.Join(rows, "\n")
-		footer := helpStyle.Render("\n↑/↓/←/→: naviguer • enter: entrer • g: explorer • s: shell • q: quitter")
-
 		return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
 	}
 
 	return ""
 }
 
-// Commande Tea pour lancer le scan en arrière-plan
-func scanDirectoryCmd(path string, counter *int64, visited map[fileID]struct{}) tea.Cmd {
-	return func() tea.Msg {
-		root, err := scanRecursively(path, nil, counter, visited)
-		return scanFinishedMsg{root: root, err: err}
+// En-tête de l'explorateur : dossier, total et états actifs (instantané, surveillance, filtres, marques)
+func (m Model) browserHeader() string {
+	title := titleStyle.Render("AED")
+	path := pathStyle.Render(m.currentNode.Path)
+	totalSize := infoStyle.Render(fmt.Sprintf("Total: %s %s", formatBytes(m.currentTotal()), m.sizeLabel()))
+	if unreadable(m.currentNode) > 0 {
+		totalSize += errorStyle.Render(" incomplet")
 	}
-}
-
-// Fonction récursive principale : parcourt le disque, calcule les tailles et trie par taille décroissante
-// Utilisation de 'visited' pour éviter de compter plusieurs fois les hardlinks
-func scanRecursively(path string, parent *FileNode, counter *int64, visited map[fileID]struct{}) (*FileNode, error) {
-	atomic.AddInt64(counter, 1)
 
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return nil, err
+	header := fmt.Sprintf("  %s  %s  (%s)  %s", title, path, totalSize, dimStyle.Render(m.sortLabel()))
+	if m.fromSnapshot {
+		header += "  " + dimStyle.Render("instantané du "+m.scannedAt.Format("02/01/2006 15:04"))
 	}
-
-	name := filepath.Base(absPath)
-	if parent == nil {
-		name = absPath
+	if m.watch != nil {
+		header += "  " + changedStyle.Render("● surveillance")
 	}
-
-	node := &FileNode{
-		Name:   name,
-		Path:   absPath,
-		IsDir:  true,
-		Parent: parent,
+	if label := mountsLabel(m.mounts); label != "" {
+		header += "  " + dimStyle.Render(label)
 	}
-
-	entries, err := os.ReadDir(absPath)
-	if err != nil {
-		return node, nil
+	if label := m.ageFilterLabel(); label != "" {
+		header += "  " + markStyle.Render(label)
 	}
-
-	var totalSize int64
-
-	for _, entry := range entries {
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
-		// Exclusion des pseudo-systèmes de fichiers sous Linux
-		if node.Path == "/" && (entry.Name() == "proc" || entry.Name() == "sys" || entry.Name() == "dev" || entry.Name() == "run") {
-			continue
+	if len(m.marked) > 0 {
+		var markedSize int64
+		for _, n := range m.marked {
+			markedSize += m.sizeOf(n)
 		}
+		header += "  " + markStyle.Render(fmt.Sprintf("%d marqué(s), %s", len(m.marked), formatBytes(markedSize)))
+	}
+	return header + "\n"
+}
 
-		childPath := filepath.Join(absPath, entry.Name())
+// Aide et statut sous la liste ; la liste complète des touches n'est affichée qu'avec ?
+func (m Model) browserFooter() string {
+	help := "↑/↓/←/→: naviguer • enter: entrer • espace: marquer • d: supprimer • /: rechercher • ?: toutes les touches • q: quitter"
+	if m.fullHelp {
+		help = "↑/↓/←/→: naviguer • enter: entrer • /: rechercher • g: explorer • s: shell • x: exclusions • !: erreurs • q: quitter\n" +
+			"m: treemap • t: types • A/f: âge • u: propriétaires • p: audit • n: nettoyage • o/r: tri • 1-4: colonnes • a: apparent/disque\n" +
+			"espace: marquer • d: supprimer • D: doublons • w: surveiller • ctrl+s: instantané • c/C: comparer (instantané/dossier) • e: export ncdu • ?: masquer"
+	}
+	footer := helpStyle.Render("\n" + help)
+	if m.status != "" {
+		footer += "\n  " + m.status
+	}
+	return footer
+}
 
-		if entry.IsDir() {
-			childNode, _ := scanRecursively(childPath, node, counter, visited)
-			if childNode != nil {
-				node.Children = append(node.Children, childNode)
-				totalSize += childNode.Size
-			}
-		} else {
-			atomic.AddInt64(counter, 1)
-
-			var size int64
-			// Calcul précis de la taille disque (blocks) et déduplication via inode/dev
-			if stat, ok := info.Sys().(*syscall.Stat_t); ok {
-				size = stat.Blocks * 512
-				id := fileID{dev: stat.Dev, ino: stat.Ino}
-				if _, seen := visited[id]; !seen {
-					visited[id] = struct{}{}
-					totalSize += size
-				}
-			} else {
-				size = info.Size()
-				totalSize += size
-			}
+// Nombre de lignes de la liste de l'explorateur : le reste de l'écran une fois l'en-tête et le pied affichés
+func (m Model) listHeight() int {
+	if m.currentNode == nil {
+		return max(m.height-7, 1)
+	}
+	// Lignes vides autour de l'en-tête et marge pour que la dernière ligne ne fasse pas défiler le terminal
+	return max(m.height-textHeight(m.browserHeader(), m.width)-textHeight(m.browserFooter(), m.width)-2, 1)
+}
 
-			child := &FileNode{
-				Name:   entry.Name(),
-				Path:   childPath,
-				Size:   size,
-				IsDir:  false,
-				Parent: node,
-			}
-			node.Children = append(node.Children, child)
+// Hauteur affichée d'un texte, lignes trop longues repliées par le terminal comprises
+func textHeight(s string, width int) int {
+	height := 0
+	for _, line := range strings.Split(s, "\n") {
+		w := lipgloss.Width(line)
+		if width <= 0 || w <= width {
+			height++
+			continue
 		}
+		height += (w + width - 1) / width
 	}
-
-	node.Size = totalSize
-
-	// Tri des enfants du plus gros au plus petit
-	sort.Slice(node.Children, func(i, j int) bool {
-		return node.Children[i].Size > node.Children[j].Size
-	})
-
-	return node, nil
+	return height
 }
 
 // Formate les octets en unité lisible (KB, MB, GB...)
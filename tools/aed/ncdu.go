@@ -0,0 +1,430 @@
+package aeddsa
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Version du format d'export ncdu produite (1.2 : champ nlink de ncdu 2)
+const (
+	ncduMajor = 1
+	ncduMinor = 2
+)
+
+// Métadonnées d'un élément au format ncdu ; pour un dossier, asize/dsize ne concernent que le dossier lui-même
+type ncduInfo struct {
+	Name      string `json:"name"`
+	Asize     int64  `json:"asize,omitempty"`
+	Dsize     int64  `json:"dsize,omitempty"`
+	Dev       uint64 `json:"dev,omitempty"`
+	Ino       uint64 `json:"ino,omitempty"`
+	Hlnkc     bool   `json:"hlnkc,omitempty"`
+	Nlink     uint64 `json:"nlink,omitempty"`
+	ReadError bool   `json:"read_error,omitempty"`
+	Excluded  string `json:"excluded,omitempty"`
+	Notreg    bool   `json:"notreg,omitempty"`
+	Mtime     int64  `json:"mtime,omitempty"` // mode étendu (ncdu -e)
+	Uid       uint32 `json:"uid,omitempty"`
+	Gid       uint32 `json:"gid,omitempty"`
+	Mode      uint32 `json:"mode,omitempty"`
+}
+
+// Indique si le chemin désigne un fichier d'arbre à charger (instantané ou export ncdu) plutôt qu'un dossier à scanner
+func isTreeFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Commande Tea de chargement d'un arbre depuis un fichier (même message qu'un scan)
+func loadTreeCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		root, exclusions, created, err := loadTree(path)
+		return scanFinishedMsg{root: root, exclusions: exclusions, err: err, snapshot: created}
+	}
+}
+
+// Charge un arbre depuis un fichier : instantané AED d'après l'extension, export ncdu sinon
+func loadTree(path string) (*FileNode, []Exclusion, time.Time, error) {
+	if isSnapshot(path) {
+		return loadSnapshot(path)
+	}
+	return importNcduFile(path)
+}
+
+// Écrit l'arbre au format d'export JSON de ncdu (ncdu -o)
+func exportNcdu(w io.Writer, root *FileNode, exclusions []Exclusion, created time.Time) error {
+	bw := bufio.NewWriter(w)
+
+	meta, _ := json.Marshal(map[string]any{"progname": "cyberTools-aed", "progver": "1", "timestamp": created.Unix()})
+	fmt.Fprintf(bw, "[%d,%d,%s,\n", ncduMajor, ncduMinor, meta)
+
+	// Les éléments exclus sont rattachés à leur dossier parent
+	excluded := make(map[string][]Exclusion)
+	for _, e := range exclusions {
+		dir := filepath.Dir(e.Path)
+		excluded[dir] = append(excluded[dir], e)
+	}
+
+	if err := writeNcduDir(bw, root, root.Path, 0, excluded); err != nil {
+		return err
+	}
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+func writeNcduDir(w *bufio.Writer, dir *FileNode, name string, parentDev uint64, excluded map[string][]Exclusion) error {
+	info := ncduInfo{Name: name, Asize: dir.OwnApparent, Dsize: dir.OwnSize, Ino: dir.Ino, ReadError: dir.ReadError,
+		Mtime: unixOrZero(dir.ModTime), Uid: dir.Uid, Gid: dir.Gid, Mode: dir.Mode}
+	if dir.Dev != parentDev {
+		info.Dev = dir.Dev
+	}
+	if err := writeNcduItem(w, "[", info); err != nil {
+		return err
+	}
+
+	for _, child := range dir.Children {
+		if child.IsDir {
+			w.WriteString(",\n")
+			if err := writeNcduDir(w, child, child.Name, dir.Dev, excluded); err != nil {
+				return err
+			}
+			continue
+		}
+		info := ncduInfo{Name: child.Name, Asize: child.Apparent, Dsize: child.Size, Ino: child.Ino, ReadError: child.ReadError,
+			Mtime: unixOrZero(child.ModTime), Uid: child.Uid, Gid: child.Gid, Mode: child.Mode}
+		if child.Dev != dir.Dev {
+			info.Dev = child.Dev
+		}
+		// Lien symbolique, périphérique, socket... (type inconnu si le mode n'a pas été relevé)
+		if child.Mode != 0 && child.Mode&syscall.S_IFMT != syscall.S_IFREG {
+			info.Notreg = true
+		}
+		if child.Nlink > 1 {
+			info.Hlnkc = true
+			info.Nlink = child.Nlink
+		}
+		if err := writeNcduItem(w, ",\n", info); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range excluded[dir.Path] {
+		info := ncduInfo{Name: filepath.Base(e.Path), Excluded: ncduExcludedReason(e)}
+		if err := writeNcduItem(w, ",\n", info); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.WriteString("]")
+	return err
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func writeNcduItem(w *bufio.Writer, prefix string, info ncduInfo) error {
+	b, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	w.WriteString(prefix)
+	_, err = w.Write(b)
+	return err
+}
+
+// Valeur du champ "excluded" de ncdu pour une exclusion AED
+// Les exclusions d'instantanés antérieurs au champ Kind sont exportées comme autre système de fichiers
+func ncduExcludedReason(e Exclusion) string {
+	switch e.Kind {
+	case excludePattern:
+		return "pattern"
+	case excludePseudo:
+		return "kernfs"
+	case excludeFirmlink:
+		return "frmlnk"
+	default: // montages en mémoire et réseau, autre système de fichiers
+		return "otherfs"
+	}
+}
+
+// Exclusion AED correspondant à un élément marqué exclu dans un export ncdu
+func ncduExclusion(path, excluded string) Exclusion {
+	switch excluded {
+	case "pattern":
+		return Exclusion{Path: path, Kind: excludePattern, Reason: "motif (ncdu)"}
+	case "otherfs":
+		return Exclusion{Path: path, Kind: excludeOtherFS, Reason: "autre système de fichiers (ncdu)"}
+	case "kernfs":
+		return Exclusion{Path: path, Kind: excludePseudo, Reason: "pseudo-système (ncdu)"}
+	case "frmlnk":
+		return Exclusion{Path: path, Kind: excludeFirmlink, Reason: "firmlink (ncdu)"}
+	}
+	return Exclusion{Path: path, Kind: excludeOtherFS, Reason: "exclu (ncdu)"}
+}
+
+// Importe un export ncdu : les tailles des dossiers sont recalculées en comptant une seule fois les liens physiques
+func importNcduFile(path string) (*FileNode, []Exclusion, time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	defer f.Close()
+
+	root, exclusions, created, err := importNcdu(bufio.NewReader(f))
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("%s: export ncdu invalide: %w", path, err)
+	}
+	return root, exclusions, created, nil
+}
+
+func importNcdu(r io.Reader) (*FileNode, []Exclusion, time.Time, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	var major, minor json.Number
+	if err := dec.Decode(&major); err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	if err := dec.Decode(&minor); err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	if major.String() != "1" {
+		return nil, nil, time.Time{}, fmt.Errorf("version majeure %s non supportée", major)
+	}
+
+	var meta struct {
+		Timestamp int64 `json:"timestamp"`
+	}
+	if err := dec.Decode(&meta); err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	imp := &ncduImporter{dec: dec, visited: make(map[fileID]struct{})}
+	root, err := imp.readDir(nil, "", 0)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	root.Name = root.Path
+
+	created := time.Now()
+	if meta.Timestamp > 0 {
+		created = time.Unix(meta.Timestamp, 0)
+	}
+	sort.Slice(imp.exclusions, func(i, j int) bool {
+		return imp.exclusions[i].Path < imp.exclusions[j].Path
+	})
+	return root, imp.exclusions, created, nil
+}
+
+// Lecteur en flux de l'arbre ncdu, pour ne pas charger tout le JSON en mémoire
+type ncduImporter struct {
+	dec        *json.Decoder
+	visited    map[fileID]struct{}
+	exclusions []Exclusion
+}
+
+// Lit un dossier dont le '[' ouvrant vient d'être consommé
+func (imp *ncduImporter) readDir(parent *FileNode, parentPath string, parentDev uint64) (*FileNode, error) {
+	info, err := imp.readInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &FileNode{Name: info.Name, Path: filepath.Join(parentPath, info.Name), IsDir: true, Parent: parent,
+		OwnSize: info.Dsize, OwnApparent: info.Asize, Dev: parentDev, Ino: info.Ino, ReadError: info.ReadError, ModTime: unixTime(info.Mtime), Uid: info.Uid, Gid: info.Gid, Mode: info.Mode}
+	if parent == nil {
+		node.Path = filepath.Clean(info.Name)
+	}
+	if info.Dev != 0 {
+		node.Dev = info.Dev
+	}
+
+	for imp.dec.More() {
+		tok, err := imp.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok {
+		case json.Delim('['):
+			child, err := imp.readDir(node, node.Path, node.Dev)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+			node.Size += child.Size
+			node.Apparent += child.Apparent
+
+		case json.Delim('{'):
+			info, err := imp.readObject()
+			if err != nil {
+				return nil, err
+			}
+			childPath := filepath.Join(node.Path, info.Name)
+			if info.Excluded != "" {
+				imp.exclusions = append(imp.exclusions, ncduExclusion(childPath, info.Excluded))
+				continue
+			}
+
+			child := &FileNode{Name: info.Name, Path: childPath, Size: info.Dsize, Apparent: info.Asize, Parent: node,
+				Dev: node.Dev, Ino: info.Ino, Nlink: info.Nlink, ReadError: info.ReadError, ModTime: unixTime(info.Mtime), Uid: info.Uid, Gid: info.Gid,
+				Mode: info.Mode}
+			if info.Dev != 0 {
+				child.Dev = info.Dev
+			}
+			if info.Hlnkc && child.Nlink == 0 {
+				child.Nlink = 2
+			}
+			node.Children = append(node.Children, child)
+
+			// Un lien physique n'est compté qu'une fois, comme pendant un scan
+			if child.Nlink > 1 {
+				id := fileID{dev: child.Dev, ino: child.Ino}
+				if _, seen := imp.visited[id]; seen {
+					child.Shared = true
+					continue
+				}
+				imp.visited[id] = struct{}{}
+			}
+			node.Size += child.Size
+			node.Apparent += child.Apparent
+
+		default:
+			return nil, fmt.Errorf("élément inattendu %v dans %s", tok, node.Path)
+		}
+	}
+
+	if err := expectDelim(imp.dec, ']'); err != nil {
+		return nil, err
+	}
+
+	finalizeDir(node)
+	return node, nil
+}
+
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// Lit l'objet d'information en tête d'un dossier
+func (imp *ncduImporter) readInfo() (ncduInfo, error) {
+	if err := expectDelim(imp.dec, '{'); err != nil {
+		return ncduInfo{}, err
+	}
+	return imp.readObject()
+}
+
+// Lit un objet dont le '{' ouvrant vient d'être consommé ; les champs inconnus sont ignorés
+func (imp *ncduImporter) readObject() (ncduInfo, error) {
+	var info ncduInfo
+	for imp.dec.More() {
+		tok, err := imp.dec.Token()
+		if err != nil {
+			return info, err
+		}
+		key, _ := tok.(string)
+
+		var raw json.RawMessage
+		if err := imp.dec.Decode(&raw); err != nil {
+			return info, err
+		}
+
+		switch key {
+		case "name":
+			err = json.Unmarshal(raw, &info.Name)
+		case "asize":
+			err = json.Unmarshal(raw, &info.Asize)
+		case "dsize":
+			err = json.Unmarshal(raw, &info.Dsize)
+		case "dev":
+			err = json.Unmarshal(raw, &info.Dev)
+		case "ino":
+			err = json.Unmarshal(raw, &info.Ino)
+		case "hlnkc":
+			err = json.Unmarshal(raw, &info.Hlnkc)
+		case "nlink":
+			err = json.Unmarshal(raw, &info.Nlink)
+		case "read_error":
+			err = json.Unmarshal(raw, &info.ReadError)
+		case "excluded":
+			err = json.Unmarshal(raw, &info.Excluded)
+		case "notreg":
+			err = json.Unmarshal(raw, &info.Notreg)
+		case "mtime":
+			err = json.Unmarshal(raw, &info.Mtime)
+		case "uid":
+			err = json.Unmarshal(raw, &info.Uid)
+		case "gid":
+			err = json.Unmarshal(raw, &info.Gid)
+		case "mode":
+			err = json.Unmarshal(raw, &info.Mode)
+		}
+		if err != nil {
+			return info, fmt.Errorf("champ %s: %w", key, err)
+		}
+	}
+	return info, expectDelim(imp.dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("%q attendu, %v trouvé", want, tok)
+	}
+	return nil
+}
+
+// Demande le fichier de destination de l'export ncdu
+func (m Model) openExportPrompt() (Model, tea.Cmd) {
+	def := strings.TrimSuffix(defaultSnapshotPath(m.root.Path, m.scannedAt), snapshotExt) + ".ncdu.json"
+	return m.openPrompt("Export ncdu", "Fichier de destination", def, func(m Model, path string) (Model, tea.Cmd) {
+		if err := m.exportNcduFile(path); err != nil {
+			m.status = errorStyle.Render("Export impossible : " + err.Error())
+		} else {
+			m.status = "Export ncdu écrit : " + path
+		}
+		return m, nil
+	})
+}
+
+func (m Model) exportNcduFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := exportNcdu(f, m.root, m.exclusions, m.scannedAt); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,151 @@
+package aeddsa
+
+import (
+	"bytes"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestNcduRoundTrip(t *testing.T) {
+	root := testRoot("/data")
+	root.OwnSize, root.OwnApparent = 4096, 4096
+	logs := testDir(root, "logs")
+	logs.OwnSize, logs.OwnApparent = 4096, 120
+	testFile(logs, "app.log", 8192).Uid = 1000
+	testFile(root, "run.sh", 512).Mode = syscall.S_IFREG | 0o4755
+	link := testFile(root, "lien", 0)
+	link.Mode = syscall.S_IFLNK | 0o777
+	exclusions := []Exclusion{{Path: "/data/node_modules", Reason: "motif node_modules"}}
+
+	var buf bytes.Buffer
+	if err := exportNcdu(&buf, root, exclusions, testTime); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{`"name":"lien","notreg":true`, `"name":"/data","asize":4096,"dsize":4096`, `"name":"logs","asize":120,"dsize":4096`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("export sans %s :\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `"name":"app.log","notreg"`) {
+		t.Errorf("fichier régulier marqué notreg :\n%s", out)
+	}
+
+	got, gotExcl, created, err := importNcdu(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.Equal(testTime) {
+		t.Errorf("date %v, attendu %v", created, testTime)
+	}
+	if len(gotExcl) != 1 || gotExcl[0].Path != "/data/node_modules" {
+		t.Errorf("exclusions %v", gotExcl)
+	}
+
+	tests := []struct {
+		path        string
+		size        int64
+		mode        uint32
+		uid         uint32
+		ownSize     int64
+		ownApparent int64
+	}{
+		{"/data", 8704, syscall.S_IFDIR | 0o755, 0, 4096, 4096},
+		{"/data/logs", 8192, syscall.S_IFDIR | 0o755, 0, 4096, 120},
+		{"/data/logs/app.log", 8192, syscall.S_IFREG | 0o644, 1000, 0, 0},
+		{"/data/run.sh", 512, syscall.S_IFREG | 0o4755, 0, 0, 0},
+		{"/data/lien", 0, syscall.S_IFLNK | 0o777, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		n := findNode(got, tt.path)
+		if n == nil {
+			t.Errorf("%s absent après import", tt.path)
+			continue
+		}
+		if n.Size != tt.size || n.Mode != tt.mode || n.Uid != tt.uid || n.OwnSize != tt.ownSize || n.OwnApparent != tt.ownApparent {
+			t.Errorf("%s : taille %d mode %o uid %d dossier %d/%d, attendu %d %o %d %d/%d", tt.path,
+				n.Size, n.Mode, n.Uid, n.OwnSize, n.OwnApparent, tt.size, tt.mode, tt.uid, tt.ownSize, tt.ownApparent)
+		}
+	}
+}
+
+func TestImportNcduHardlinks(t *testing.T) {
+	// Deux liens du même inode : comptés une seule fois dans le total du dossier
+	in := `[1,2,{"progname":"ncdu","timestamp":0},
+[{"name":"/r"},
+{"name":"a","asize":100,"dsize":4096,"ino":7,"hlnkc":true,"nlink":2},
+{"name":"b","asize":100,"dsize":4096,"ino":7,"hlnkc":true,"nlink":2},
+{"name":"c","asize":10,"dsize":4096}]]`
+	root, _, _, err := importNcdu(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Size != 8192 || root.Apparent != 110 || len(root.Children) != 3 {
+		t.Errorf("total %d/%d pour %d enfants, attendu 8192/110 pour 3", root.Size, root.Apparent, len(root.Children))
+	}
+}
+
+func TestImportNcduInvalid(t *testing.T) {
+	tests := []string{
+		``,
+		`{}`,
+		`[2,0,{},[{"name":"/"}]]`,
+		`[1,0,{},[{"name":"/"},42]]`,
+	}
+	for _, in := range tests {
+		if _, _, _, err := importNcdu(strings.NewReader(in)); err == nil {
+			t.Errorf("importNcdu(%q) : erreur attendue", in)
+		}
+	}
+}
+
+// Chaque nature d'exclusion est exportée avec la valeur ncdu correspondante, puis relue avec la même nature
+func TestNcduExcludedKinds(t *testing.T) {
+	root := testRoot("/")
+	tests := []struct {
+		excl Exclusion
+		want string
+		kind string
+	}{
+		{Exclusion{Path: "/node_modules", Kind: excludePattern, Reason: "motif node_modules"}, "pattern", excludePattern},
+		{Exclusion{Path: "/proc", Kind: excludePseudo, Reason: "pseudo-système (proc)"}, "kernfs", excludePseudo},
+		{Exclusion{Path: "/tmp", Kind: excludeMemory, Reason: "montage en mémoire (tmpfs)"}, "otherfs", excludeOtherFS},
+		{Exclusion{Path: "/mnt", Kind: excludeNetwork, Reason: "montage réseau (nfs4)"}, "otherfs", excludeOtherFS},
+		{Exclusion{Path: "/media", Kind: excludeOtherFS, Reason: "autre système de fichiers"}, "otherfs", excludeOtherFS},
+		{Exclusion{Path: "/System", Kind: excludeFirmlink, Reason: "firmlink (ncdu)"}, "frmlnk", excludeFirmlink},
+	}
+
+	var exclusions []Exclusion
+	for _, tt := range tests {
+		exclusions = append(exclusions, tt.excl)
+	}
+	var buf bytes.Buffer
+	if err := exportNcdu(&buf, root, exclusions, testTime); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, tt := range tests {
+		want := `"name":"` + tt.excl.Path[1:] + `","excluded":"` + tt.want + `"`
+		if !strings.Contains(out, want) {
+			t.Errorf("export sans %s :\n%s", want, out)
+		}
+	}
+
+	_, got, _, err := importNcdu(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("%d exclusions relues, attendu %d", len(got), len(tests))
+	}
+	kinds := make(map[string]string)
+	for _, e := range got {
+		kinds[e.Path] = e.Kind
+	}
+	for _, tt := range tests {
+		if kind := kinds[tt.excl.Path]; kind != tt.kind {
+			t.Errorf("%s relu avec la nature %q, attendu %q", tt.excl.Path, kind, tt.kind)
+		}
+	}
+}
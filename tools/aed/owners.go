@@ -0,0 +1,205 @@
+package aeddsa
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Nombre maximal de dossiers listés pour un propriétaire
+const ownerDirsLimit = 200
+
+// Occupation d'un utilisateur ou d'un groupe
+type ownerStat struct {
+	id   uint32
+	name string
+	usageStat
+}
+
+// Dossier et octets qu'y possède le propriétaire choisi
+type ownerDir struct {
+	node *FileNode
+	usageStat
+}
+
+// Identifiant du propriétaire d'un fichier : utilisateur ou groupe
+func ownerID(n *FileNode, byGroup bool) uint32 {
+	if byGroup {
+		return n.Gid
+	}
+	return n.Uid
+}
+
+// Occupation par propriétaire des fichiers du sous-arbre, de la plus grosse à la plus petite
+func ownerStats(files []*FileNode, byGroup, apparent bool) []ownerStat {
+	index := make(map[uint32]int)
+	var stats []ownerStat
+	for _, f := range files {
+		id := ownerID(f, byGroup)
+		i, ok := index[id]
+		if !ok {
+			i = len(stats)
+			index[id] = i
+			name := userName(id)
+			if byGroup {
+				name = groupName(id)
+			}
+			stats = append(stats, ownerStat{id: id, name: name})
+		}
+		stats[i].add(f)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if a, b := stats[i].bytes(apparent), stats[j].bytes(apparent); a != b {
+			return a > b
+		}
+		return stats[i].name < stats[j].name
+	})
+	return stats
+}
+
+// Dossiers du sous-arbre classés par octets appartenant au propriétaire, tous niveaux confondus
+func ownerDirs(root *FileNode, files []*FileNode, id uint32, byGroup, apparent bool) []ownerDir {
+	byDir := make(map[*FileNode]*ownerDir)
+	for _, f := range files {
+		if ownerID(f, byGroup) != id {
+			continue
+		}
+		for p := f.Parent; p != nil; p = p.Parent {
+			d, ok := byDir[p]
+			if !ok {
+				d = &ownerDir{node: p}
+				byDir[p] = d
+			}
+			d.add(f)
+			if p == root {
+				break
+			}
+		}
+	}
+
+	dirs := make([]ownerDir, 0, len(byDir))
+	for _, d := range byDir {
+		dirs = append(dirs, *d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if a, b := dirs[i].bytes(apparent), dirs[j].bytes(apparent); a != b {
+			return a > b
+		}
+		return dirs[i].node.Path < dirs[j].node.Path
+	})
+	if len(dirs) > ownerDirsLimit {
+		dirs = dirs[:ownerDirsLimit]
+	}
+	return dirs
+}
+
+// Ouvre la répartition du dossier courant par utilisateur
+func (m Model) openOwners() Model {
+	m.ownerRoot = m.currentNode
+	m.ownerFiles = subtreeFiles(m.currentNode)
+	m.ownerByGroup = false
+	m.owners = ownerStats(m.ownerFiles, false, m.apparent)
+	m.panel.reset()
+	m.state = StateOwners
+	return m
+}
+
+func (m Model) updateOwners(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "u":
+		m.state = StateBrowsing
+		return m, nil
+	case "tab":
+		m.ownerByGroup = !m.ownerByGroup
+		m.owners = ownerStats(m.ownerFiles, m.ownerByGroup, m.apparent)
+		m.panel.reset()
+		return m, nil
+	case "enter", "right", "l":
+		if m.panel.cursor < len(m.owners) {
+			m.ownerSel = m.panel.cursor
+			m.ownerDirs = ownerDirs(m.ownerRoot, m.ownerFiles, m.owners[m.ownerSel].id, m.ownerByGroup, m.apparent)
+			m.ownerPanel = m.panel
+			m.panel.reset()
+			m.state = StateOwnerDirs
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(m.owners), m.height-8)
+	return m, nil
+}
+
+func (m Model) viewOwners() string {
+	title := titleStyle.Render("AED - Propriétaires")
+	tabs := []string{"utilisateurs", "groupes"}
+	sel := 0
+	if m.ownerByGroup {
+		sel = 1
+	}
+	tabs[sel] = pathStyle.Render("[" + tabs[sel] + "]")
+	header := fmt.Sprintf("  %s  %s  (%s)  %s %s\n", title, pathStyle.Render(m.ownerRoot.Path),
+		infoStyle.Render(fmt.Sprintf("%d fichiers", len(m.ownerFiles))), tabs[0], tabs[1])
+
+	var content string
+	if len(m.owners) == 0 {
+		content = "  Aucun fichier."
+	} else {
+		var total int64
+		for _, o := range m.owners {
+			total += o.bytes(m.apparent)
+		}
+		rows := make([]string, len(m.owners))
+		for i, o := range m.owners {
+			percent := 0.0
+			if total > 0 {
+				percent = float64(o.bytes(m.apparent)) / float64(total) * 100
+			}
+			rows[i] = fmt.Sprintf("%-20.20s %8d  %8d  %10s  %5.1f%%", o.name, o.id, o.count, formatBytes(o.bytes(m.apparent)), percent)
+		}
+		content = dimStyle.Render(fmt.Sprintf("  %-20s %8s  %8s  %10s  %6s", "nom", "id", "fichiers", "taille", "part")) + "\n" +
+			m.panel.render(rows, m.height-9, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • tab: utilisateurs/groupes • enter: plus gros dossiers • esc: retour")
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
+
+// Plus gros dossiers du propriétaire choisi ; enter ouvre le dossier dans l'explorateur
+func (m Model) updateOwnerDirs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "backspace", "left", "h":
+		m.panel = m.ownerPanel
+		m.state = StateOwners
+		return m, nil
+	case "enter":
+		if m.panel.cursor < len(m.ownerDirs) {
+			m.currentNode = m.ownerDirs[m.panel.cursor].node
+			m.cursor, m.yOffset = 0, 0
+			m.state = StateBrowsing
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(m.ownerDirs), m.height-8)
+	return m, nil
+}
+
+func (m Model) viewOwnerDirs() string {
+	o := m.owners[m.ownerSel]
+	kind := "Utilisateur"
+	if m.ownerByGroup {
+		kind = "Groupe"
+	}
+	title := titleStyle.Render(fmt.Sprintf("AED - %s %s", kind, o.name))
+	header := fmt.Sprintf("  %s  %s\n", title, infoStyle.Render(fmt.Sprintf("%d fichier(s), %s", o.count, formatBytes(o.bytes(m.apparent)))))
+
+	rows := make([]string, len(m.ownerDirs))
+	for i, d := range m.ownerDirs {
+		rows[i] = fmt.Sprintf("%10s  %s  %s/", formatBytes(d.bytes(m.apparent)),
+			dimStyle.Render(fmt.Sprintf("%7d fichier(s)", d.count)), d.node.Path)
+	}
+	content := m.panel.render(rows, m.height-8, m.width)
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • enter: ouvrir le dossier • esc: retour")
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
@@ -0,0 +1,80 @@
+package aeddsa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Liste défilante générique utilisée par les vues secondaires (exclusions, résultats, rapports...)
+type listPanel struct {
+	cursor  int
+	yOffset int
+}
+
+// Gère les touches de déplacement communes, renvoie true si la touche a été consommée
+func (p *listPanel) handleKey(key string, count, visibleHeight int) bool {
+	switch key {
+	case "up", "k":
+		p.move(-1, count, visibleHeight)
+	case "down", "j":
+		p.move(1, count, visibleHeight)
+	case "pgup":
+		p.move(-visibleHeight, count, visibleHeight)
+	case "pgdown":
+		p.move(visibleHeight, count, visibleHeight)
+	case "home", "g":
+		p.move(-count, count, visibleHeight)
+	case "end", "G":
+		p.move(count, count, visibleHeight)
+	default:
+		return false
+	}
+	return true
+}
+
+// Déplace le curseur en gardant la sélection dans la zone visible
+func (p *listPanel) move(delta, count, visibleHeight int) {
+	if count == 0 {
+		p.cursor, p.yOffset = 0, 0
+		return
+	}
+	p.cursor += delta
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+	if p.cursor > count-1 {
+		p.cursor = count - 1
+	}
+	if p.cursor < p.yOffset {
+		p.yOffset = p.cursor
+	}
+	if visibleHeight > 0 && p.cursor >= p.yOffset+visibleHeight {
+		p.yOffset = p.cursor - visibleHeight + 1
+	}
+}
+
+// Remet le panneau en haut de liste
+func (p *listPanel) reset() {
+	p.cursor, p.yOffset = 0, 0
+}
+
+// Affiche les lignes visibles avec la ligne du curseur en surbrillance
+func (p listPanel) render(rows []string, visibleHeight, width int) string {
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+	end := p.yOffset + visibleHeight
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	var out []string
+	for i := p.yOffset; i < end; i++ {
+		if i == p.cursor {
+			out = append(out, selectedStyle.Render(fmt.Sprintf("%-*s", width-4, rows[i])))
+		} else {
+			out = append(out, "  "+rows[i])
+		}
+	}
+	return strings.Join(out, "\n")
+}
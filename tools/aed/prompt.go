@@ -0,0 +1,65 @@
+package aeddsa
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Saisie d'une valeur depuis la navigation (instantané à comparer, fichier d'export...)
+type prompt struct {
+	input  textinput.Model
+	title  string
+	label  string
+	back   SessionState // vue retrouvée après la saisie
+	submit func(m Model, value string) (Model, tea.Cmd)
+}
+
+func newPromptInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 512
+	ti.Width = 70
+	return ti
+}
+
+// Ouvre la saisie pré-remplie avec la valeur donnée
+func (m Model) openPrompt(title, label, value string, submit func(Model, string) (Model, tea.Cmd)) (Model, tea.Cmd) {
+	m.prompt.title = title
+	m.prompt.label = label
+	m.prompt.submit = submit
+	m.prompt.back = m.state
+	m.prompt.input.SetValue(value)
+	m.prompt.input.CursorEnd()
+	m.prompt.input.Focus()
+	m.state = StatePrompt
+	return m, textinput.Blink
+}
+
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.prompt.input.Blur()
+		m.state = m.prompt.back
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.prompt.input.Value())
+		if value == "" {
+			return m, nil
+		}
+		m.prompt.input.Blur()
+		m.state = m.prompt.back
+		return m.prompt.submit(m, value)
+	}
+	var cmd tea.Cmd
+	m.prompt.input, cmd = m.prompt.input.Update(msg)
+	return m, cmd
+}
+
+func (m Model) viewPrompt() string {
+	title := titleStyle.Render("AED - " + m.prompt.title)
+	return fmt.Sprintf("\n  %s  %s\n\n  %s :\n  %s\n\n  %s",
+		title, pathStyle.Render(m.root.Path), m.prompt.label, m.prompt.input.View(),
+		helpStyle.Render("(enter: valider • esc: annuler)"))
+}
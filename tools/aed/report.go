@@ -0,0 +1,351 @@
+package aeddsa
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Codes de sortie du mode rapport
+const (
+	exitOK             = 0
+	exitError          = 1
+	exitUsage          = 2
+	exitBudgetExceeded = 3
+)
+
+// Entrée du rapport (dossier ou fichier)
+type reportEntry struct {
+	Path  string `json:"path"`
+	Type  string `json:"type"`
+	Size  int64  `json:"size"`
+	Depth int    `json:"depth"`
+}
+
+// Résultat de la vérification d'un budget de taille
+type budgetResult struct {
+	Path     string `json:"path"`
+	Limit    int64  `json:"limit"`
+	Size     int64  `json:"size"`
+	Exceeded bool   `json:"exceeded"`
+}
+
+// Document complet produit par le format JSON
+type report struct {
+	Root     string         `json:"root"`
+	Total    int64          `json:"total"`
+	Entries  []reportEntry  `json:"entries"`
+	Budgets  []budgetResult `json:"budgets,omitempty"`
+	Excluded []Exclusion    `json:"excluded,omitempty"`
+}
+
+// Option répétable de la ligne de commande (budgets, exclusions)
+type multiFlag []string
+
+func (f *multiFlag) String() string     { return strings.Join(*f, ",") }
+func (f *multiFlag) Set(v string) error { *f = append(*f, v); return nil }
+
+// Mode non interactif : scanne le dossier et affiche les plus gros éléments (table, JSON ou CSV)
+// Renvoie 3 si un budget est dépassé, pour une utilisation depuis cron ou la CI
+func RunReport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("aed --report", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	format := fs.String("format", "table", "format de sortie : table, json, csv ou ncdu (arbre complet, compatible ncdu -f)")
+	top := fs.Int("top", 20, "nombre d'éléments à afficher (0 = tous)")
+	depth := fs.Int("depth", 0, "profondeur maximale des éléments listés (0 = illimitée)")
+	threshold := fs.String("threshold", "0", "taille minimale des éléments listés (ex: 100M)")
+	kind := fs.String("type", "all", "éléments listés : all, dirs ou files")
+	var budgets, excludes multiFlag
+	fs.Var(&budgets, "budget", "budget de taille [chemin=]TAILLE, répétable (ex: 50G ou /var/log=2G)")
+	fs.Var(&excludes, "exclude", "motif glob ou re:regex à exclure, répétable")
+	oneFS := fs.Bool("one-file-system", false, "ne pas traverser les autres systèmes de fichiers")
+	skipPseudo := fs.Bool("skip-pseudo", true, "ignorer les pseudo-systèmes de fichiers du noyau (proc, sysfs...)")
+	skipVirtual := fs.Bool("skip-virtual", false, "ignorer aussi les montages en mémoire (tmpfs) et réseau")
+	save := fs.String("save", "", "enregistre aussi le scan dans un instantané (fichier ou dossier)")
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cyberTools aed --report [options] <dossier|instantané|export ncdu>")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	minSize, err := parseSize(*threshold)
+	if err != nil {
+		fmt.Fprintf(stderr, "threshold: %v\n", err)
+		return exitUsage
+	}
+	if *format != "table" && *format != "json" && *format != "csv" && *format != "ncdu" {
+		fmt.Fprintf(stderr, "format inconnu : %s\n", *format)
+		return exitUsage
+	}
+	if *kind != "all" && *kind != "dirs" && *kind != "files" {
+		fmt.Fprintf(stderr, "type inconnu : %s\n", *kind)
+		return exitUsage
+	}
+	// Les budgets sont vérifiés avant le scan, qui peut durer plusieurs minutes
+	limits, err := parseBudgets(budgets)
+	if err != nil {
+		fmt.Fprintf(stderr, "budget: %v\n", err)
+		return exitUsage
+	}
+
+	path := fs.Arg(0)
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	// Un fichier est relu comme instantané ou export ncdu au lieu d'être scanné
+	var root *FileNode
+	var excluded []Exclusion
+	created := time.Now()
+	if info.IsDir() {
+		opts := ScanOptions{Excludes: excludes, OneFileSystem: *oneFS, SkipPseudo: *skipPseudo, SkipVirtual: *skipVirtual}
+		root, excluded, err = scanRecursively(context.Background(), path, opts, newScanProgress())
+	} else {
+		root, excluded, created, err = loadTree(path)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if *save != "" {
+		if err := saveReportSnapshot(*save, root, excluded, created); err != nil {
+			fmt.Fprintf(stderr, "save: %v\n", err)
+			return exitError
+		}
+	}
+
+	entries := collectEntries(root, *depth, minSize, *kind)
+	if *top > 0 && len(entries) > *top {
+		entries = entries[:*top]
+	}
+
+	results, err := checkBudgets(root, limits)
+	if err != nil {
+		fmt.Fprintf(stderr, "budget: %v\n", err)
+		return exitUsage
+	}
+
+	rep := report{Root: root.Path, Total: root.Size, Entries: entries, Budgets: results, Excluded: excluded}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rep)
+	case "csv":
+		err = writeCSV(stdout, rep)
+	case "ncdu":
+		err = exportNcdu(stdout, root, excluded, created)
+	default:
+		err = writeTable(stdout, rep)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if n := unreadable(root); n > 0 {
+		fmt.Fprintf(stderr, "Scan incomplet : %d élément(s) illisible(s)\n", n)
+	}
+
+	code := exitOK
+	for _, b := range results {
+		if b.Exceeded {
+			fmt.Fprintf(stderr, "Budget dépassé : %s (%s > %s)\n", b.Path, formatBytes(b.Size), formatBytes(b.Limit))
+			code = exitBudgetExceeded
+		}
+	}
+	return code
+}
+
+// Enregistre l'instantané du rapport ; un dossier reçoit le nom de fichier par défaut
+func saveReportSnapshot(dest string, root *FileNode, excluded []Exclusion, created time.Time) error {
+	if info, err := os.Stat(dest); strings.HasSuffix(dest, string(filepath.Separator)) || (err == nil && info.IsDir()) {
+		dest = filepath.Join(dest, filepath.Base(defaultSnapshotPath(root.Path, created)))
+	}
+	return saveSnapshot(dest, root, excluded, created)
+}
+
+// Parcourt l'arbre et renvoie les éléments triés par taille décroissante
+func collectEntries(root *FileNode, maxDepth int, minSize int64, kind string) []reportEntry {
+	var entries []reportEntry
+
+	var walk func(node *FileNode, depth int)
+	walk = func(node *FileNode, depth int) {
+		if maxDepth > 0 && depth > maxDepth {
+			return
+		}
+		for _, child := range node.Children {
+			if child.Size >= minSize && (kind == "all" || (kind == "dirs") == child.IsDir) {
+				t := "file"
+				if child.IsDir {
+					t = "dir"
+				}
+				entries = append(entries, reportEntry{Path: child.Path, Type: t, Size: child.Size, Depth: depth})
+			}
+			if child.IsDir {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk(root, 1)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Size > entries[j].Size
+	})
+	return entries
+}
+
+// Budget de taille lu sur la ligne de commande ; sans chemin, il porte sur la racine analysée
+type budget struct {
+	path  string // chemin absolu, vide pour la racine
+	limit int64
+}
+
+// Lit les budgets [chemin=]TAILLE
+func parseBudgets(budgets []string) ([]budget, error) {
+	var parsed []budget
+	for _, b := range budgets {
+		var path string
+		limitStr := b
+		if i := strings.LastIndex(b, "="); i >= 0 {
+			path, limitStr = b[:i], b[i+1:]
+		}
+
+		limit, err := parseSize(limitStr)
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			if path, err = filepath.Abs(path); err != nil {
+				return nil, err
+			}
+		}
+		parsed = append(parsed, budget{path: path, limit: limit})
+	}
+	return parsed, nil
+}
+
+// Vérifie chaque budget déclaré par rapport aux tailles calculées
+func checkBudgets(root *FileNode, budgets []budget) ([]budgetResult, error) {
+	var results []budgetResult
+
+	for _, b := range budgets {
+		path := b.path
+		if path == "" {
+			path = root.Path
+		}
+		node := findNode(root, path)
+		if node == nil {
+			return nil, fmt.Errorf("%s n'appartient pas au dossier analysé", path)
+		}
+
+		results = append(results, budgetResult{
+			Path:     node.Path,
+			Limit:    b.limit,
+			Size:     node.Size,
+			Exceeded: node.Size > b.limit,
+		})
+	}
+	return results, nil
+}
+
+// Retrouve un nœud de l'arbre à partir de son chemin absolu
+func findNode(root *FileNode, path string) *FileNode {
+	if root.Path == path {
+		return root
+	}
+	rel, err := filepath.Rel(root.Path, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+
+	node := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		var next *FileNode
+		for _, child := range node.Children {
+			if child.Name == part {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+func writeTable(w io.Writer, rep report) error {
+	fmt.Fprintf(w, "%10s  %-5s  %s\n", "TAILLE", "TYPE", "CHEMIN")
+	for _, e := range rep.Entries {
+		fmt.Fprintf(w, "%10s  %-5s  %s\n", formatBytes(e.Size), e.Type, e.Path)
+	}
+	_, err := fmt.Fprintf(w, "%10s  %-5s  %s\n", formatBytes(rep.Total), "total", rep.Root)
+	if err == nil && len(rep.Excluded) > 0 {
+		_, err = fmt.Fprintf(w, "%d élément(s) exclu(s)\n", len(rep.Excluded))
+	}
+	return err
+}
+
+func writeCSV(w io.Writer, rep report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"path", "type", "size", "depth"})
+	for _, e := range rep.Entries {
+		cw.Write([]string{e.Path, e.Type, strconv.FormatInt(e.Size, 10), strconv.Itoa(e.Depth)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Convertit une taille lisible (512, 100K, 1.5G, 2TiB...) en octets, en base 1024 comme formatBytes
+// Le "i" n'est accepté que juste après l'unité ; les valeurs non finies ou hors de int64 sont refusées
+func parseSize(s string) (int64, error) {
+	invalid := fmt.Errorf("taille invalide : %q", s)
+	str := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+
+	mult := 1.0
+	if i := strings.IndexAny(str, "KMGTPE"); i >= 0 {
+		unit := str[i:]
+		if len(unit) > 2 || (len(unit) == 2 && unit[1] != 'I') {
+			return 0, invalid
+		}
+		for n := strings.IndexByte("KMGTPE", unit[0]); n >= 0; n-- {
+			mult *= 1024
+		}
+		str = strings.TrimSpace(str[:i])
+	}
+
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) || v*mult >= math.MaxInt64 {
+		return 0, invalid
+	}
+	return int64(v * mult), nil
+}
@@ -0,0 +1,62 @@
+package aeddsa
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"512", 512, false},
+		{"100K", 100 << 10, false},
+		{"100k", 100 << 10, false},
+		{"1.5G", 3 << 29, false},
+		{"2TiB", 2 << 40, false},
+		{"10MB", 10 << 20, false},
+		{" 4M ", 4 << 20, false},
+		{"1E", 1 << 60, false},
+		{"", 0, true},
+		{"G", 0, true},
+		{"-1K", 0, true},
+		{"12X", 0, true},
+		{"inf", 0, true},
+		{"NaN", 0, true},
+		{"1e30", 0, true},
+		{"8E", 0, true},
+		{"9999999P", 0, true},
+		{"10I", 0, true},
+		{"5IB", 0, true},
+		{"5KIB", 5 << 10, false},
+		{"5KII", 0, true},
+		{"K5", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) erreur = %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, attendu %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// Un budget invalide est refusé avant tout scan ou chargement
+func TestRunReportInvalidBudget(t *testing.T) {
+	for _, b := range []string{"10X", "/tmp=inf", "=5IB"} {
+		var out, errOut strings.Builder
+		if code := RunReport([]string{"--budget", b, t.TempDir()}, &out, &errOut); code != exitUsage {
+			t.Errorf("--budget %s : code %d, attendu %d", b, code, exitUsage)
+		}
+		if out.Len() != 0 {
+			t.Errorf("--budget %s : rapport produit malgré l'erreur", b)
+		}
+	}
+}
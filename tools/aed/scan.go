@@ -0,0 +1,275 @@
+package aeddsa
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Identifiant unique d'un fichier (device + inode) pour gérer les liens physiques
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// Nombre maximal de dossiers lus en parallèle
+var scanWorkers = runtime.NumCPU() * 4
+
+// Progression du scan, lue par la vue pendant que les workers l'alimentent
+type scanProgress struct {
+	files   atomic.Int64
+	bytes   atomic.Int64
+	current atomic.Value // string : dernier dossier ouvert
+	start   time.Time
+}
+
+func newScanProgress() *scanProgress {
+	p := &scanProgress{start: time.Now()}
+	p.current.Store("")
+	return p
+}
+
+// Débit moyen depuis le début du scan (octets par seconde)
+func (p *scanProgress) throughput() int64 {
+	elapsed := time.Since(p.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return int64(float64(p.bytes.Load()) / elapsed)
+}
+
+// Scanner parallèle : pool de workers borné par un sémaphore et annulation via le contexte
+type scanner struct {
+	ctx      context.Context
+	sem      chan struct{}
+	progress *scanProgress
+
+	exclude *excluder
+
+	mu         sync.Mutex
+	visited    map[fileID]struct{}
+	exclusions []Exclusion
+}
+
+// Marque un inode comme vu, renvoie false s'il l'était déjà (lien physique)
+func (s *scanner) markVisited(id fileID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, seen := s.visited[id]; seen {
+		return false
+	}
+	s.visited[id] = struct{}{}
+	return true
+}
+
+// Enregistre un élément ignoré
+func (s *scanner) addExclusion(e Exclusion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exclusions = append(s.exclusions, e)
+}
+
+// Commande Tea pour lancer le scan en arrière-plan
+func scanDirectoryCmd(ctx context.Context, path string, opts ScanOptions, progress *scanProgress) tea.Cmd {
+	return func() tea.Msg {
+		root, exclusions, err := scanRecursively(ctx, path, opts, progress)
+		return scanFinishedMsg{root: root, exclusions: exclusions, err: err}
+	}
+}
+
+// Point d'entrée du scan : parcourt le disque, calcule les tailles et trie par taille décroissante
+// Renvoie aussi la liste des éléments exclus, ou l'erreur du contexte si le scan a été annulé
+func scanRecursively(ctx context.Context, path string, opts ScanOptions, progress *scanProgress) (*FileNode, []Exclusion, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rootDev uint64
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		rootDev = stat.Dev
+	}
+
+	exclude, err := newExcluder(opts, rootDev)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := &scanner{
+		ctx:      ctx,
+		sem:      make(chan struct{}, scanWorkers),
+		progress: progress,
+		exclude:  exclude,
+		visited:  make(map[fileID]struct{}),
+	}
+
+	root := s.scanDir(absPath, absPath, nil, info)
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(s.exclusions, func(i, j int) bool {
+		return s.exclusions[i].Path < s.exclusions[j].Path
+	})
+	return root, s.exclusions, nil
+}
+
+// Scan d'un dossier : les sous-dossiers sont confiés à un worker libre, ou scannés sur place si le pool est plein
+func (s *scanner) scanDir(absPath, name string, parent *FileNode, info os.FileInfo) *FileNode {
+	s.progress.files.Add(1)
+	s.progress.current.Store(absPath)
+
+	node := &FileNode{
+		Name:    name,
+		Path:    absPath,
+		IsDir:   true,
+		ModTime: info.ModTime(),
+		Parent:  parent,
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		node.Dev, node.Ino, node.Nlink, node.Mode = stat.Dev, stat.Ino, uint64(stat.Nlink), stat.Mode
+		node.Uid, node.Gid = stat.Uid, stat.Gid
+		node.AccessTime = time.Unix(stat.Atim.Unix())
+		node.OwnSize, node.OwnApparent = stat.Blocks*512, info.Size()
+	}
+
+	if s.ctx.Err() != nil {
+		return node
+	}
+
+	// Une erreur d'E/S peut survenir après une lecture partielle : les entrées déjà lues sont gardées
+	entries, err := os.ReadDir(absPath)
+	if err != nil {
+		node.ReadError = true
+		node.ErrorMsg = scanErrorMsg(err)
+	}
+
+	var totalSize, totalApparent int64
+	var wg sync.WaitGroup
+	subdirs := make([]*FileNode, len(entries))
+
+	for i, entry := range entries {
+		if s.ctx.Err() != nil {
+			break
+		}
+
+		childPath := filepath.Join(absPath, entry.Name())
+
+		// Élément disparu depuis la lecture du dossier : ignoré, sinon gardé comme illisible
+		info, err := entry.Info()
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				node.Children = append(node.Children, &FileNode{Name: entry.Name(), Path: childPath, IsDir: entry.IsDir(),
+					Parent: node, ReadError: true, ErrorMsg: scanErrorMsg(err)})
+			}
+			continue
+		}
+
+		stat, hasStat := info.Sys().(*syscall.Stat_t)
+		dev := s.exclude.rootDev
+		if hasStat {
+			dev = stat.Dev
+		}
+
+		// Exclusions utilisateur, montages virtuels/réseau et changement de système de fichiers
+		if excl, skip := s.exclude.match(childPath, entry.Name(), entry.IsDir(), dev); skip {
+			s.addExclusion(excl)
+			continue
+		}
+
+		if entry.IsDir() {
+			select {
+			case s.sem <- struct{}{}:
+				wg.Add(1)
+				go func(i int, p, n string, fi os.FileInfo) {
+					defer wg.Done()
+					defer func() { <-s.sem }()
+					subdirs[i] = s.scanDir(p, n, node, fi)
+				}(i, childPath, entry.Name(), info)
+			default:
+				subdirs[i] = s.scanDir(childPath, entry.Name(), node, info)
+			}
+			continue
+		}
+
+		s.progress.files.Add(1)
+
+		child := newFileNode(childPath, info, node)
+
+		// Déduplication des liens physiques via inode/dev
+		if !hasStat || s.markVisited(fileID{dev: stat.Dev, ino: stat.Ino}) {
+			totalSize += child.Size
+			totalApparent += child.Apparent
+			s.progress.bytes.Add(child.Size)
+		} else {
+			child.Shared = true
+		}
+
+		node.Children = append(node.Children, child)
+	}
+
+	wg.Wait()
+
+	for _, child := range subdirs {
+		if child != nil {
+			node.Children = append(node.Children, child)
+			totalSize += child.Size
+			totalApparent += child.Apparent
+		}
+	}
+
+	node.Size = totalSize
+	node.Apparent = totalApparent
+	finalizeDir(node)
+
+	return node
+}
+
+// Nœud d'un fichier avec sa taille disque précise (blocks) et ses métadonnées
+func newFileNode(path string, info os.FileInfo, parent *FileNode) *FileNode {
+	node := &FileNode{
+		Name:     info.Name(),
+		Path:     path,
+		Size:     info.Size(),
+		Apparent: info.Size(),
+		ModTime:  info.ModTime(),
+		Parent:   parent,
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		node.Size = stat.Blocks * 512
+		node.Dev, node.Ino, node.Nlink, node.Mode = stat.Dev, stat.Ino, uint64(stat.Nlink), stat.Mode
+		node.Uid, node.Gid = stat.Uid, stat.Gid
+		node.AccessTime = time.Unix(stat.Atim.Unix())
+	}
+	return node
+}
+
+// Complète un dossier dont les enfants sont connus : nombre d'éléments (dont illisibles), modification et accès les plus récents
+// et tri des enfants du plus gros au plus petit
+func finalizeDir(node *FileNode) {
+	for _, child := range node.Children {
+		node.Count += 1 + child.Count
+		node.Unreadable += child.Unreadable
+		if child.ReadError {
+			node.Unreadable++
+		}
+		node.absorbTimes(child)
+	}
+	sort.Slice(node.Children, func(i, j int) bool {
+		return node.Children[i].Size > node.Children[j].Size
+	})
+}
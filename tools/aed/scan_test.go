@@ -0,0 +1,26 @@
+package aeddsa
+
+import "testing"
+
+// Un dossier garde ses propres dates ; celles de son contenu sont cumulées à part
+func TestFinalizeDirTimes(t *testing.T) {
+	root := testRoot("/r")
+	sub := testDir(root, "sous")
+	f := testFile(sub, "f", 10)
+	f.ModTime, f.AccessTime = testTime.AddDate(0, 0, 3), testTime.AddDate(0, 0, 5)
+	sub.AccessTime = testTime.AddDate(0, 0, 7)
+
+	finalizeDir(sub)
+	finalizeDir(root)
+
+	if !root.ModTime.Equal(testTime) || !sub.ModTime.Equal(testTime) {
+		t.Errorf("date de modification des dossiers remplacée : %v, %v", root.ModTime, sub.ModTime)
+	}
+	if !root.lastModified().Equal(f.ModTime) || !sub.lastModified().Equal(f.ModTime) {
+		t.Errorf("modification la plus récente %v et %v, attendu %v", root.lastModified(), sub.lastModified(), f.ModTime)
+	}
+	// L'accès au dossier lui-même, plus récent que celui de son contenu, l'emporte
+	if !root.lastAccessed().Equal(sub.AccessTime) || !sub.NewestAccess.Equal(f.AccessTime) {
+		t.Errorf("accès le plus récent %v, attendu %v", root.lastAccessed(), sub.AccessTime)
+	}
+}
@@ -0,0 +1,167 @@
+package aeddsa
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Nombre maximal de résultats conservés (les plus gros)
+const searchLimit = 1000
+
+// Compile la recherche : regex sur le chemin complet si préfixée par "re:", motif glob
+// (sur le nom, ou sur le chemin complet s'il contient un /), sinon texte contenu dans le nom, sans casse
+func compileSearch(query string) (func(*FileNode) bool, error) {
+	if expr, ok := strings.CutPrefix(query, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("regex invalide %q: %w", expr, err)
+		}
+		return func(n *FileNode) bool { return re.MatchString(n.Path) }, nil
+	}
+
+	if strings.ContainsAny(query, "*?[") {
+		if _, err := filepath.Match(query, ""); err != nil {
+			return nil, fmt.Errorf("motif invalide %q: %w", query, err)
+		}
+		onPath := strings.Contains(query, "/")
+		return func(n *FileNode) bool {
+			target := n.Name
+			if onPath {
+				target = n.Path
+			}
+			ok, _ := filepath.Match(query, target)
+			return ok
+		}, nil
+	}
+
+	lower := strings.ToLower(query)
+	return func(n *FileNode) bool { return strings.Contains(strings.ToLower(n.Name), lower) }, nil
+}
+
+// Parcourt tout l'arbre (hors racine) et renvoie les éléments correspondants, du plus gros au plus petit
+// Le second résultat indique que la liste a été tronquée
+func searchTree(root *FileNode, match func(*FileNode) bool, size func(*FileNode) int64) ([]*FileNode, bool) {
+	var found []*FileNode
+	var walk func(n *FileNode)
+	walk = func(n *FileNode) {
+		for _, child := range n.Children {
+			if match(child) {
+				found = append(found, child)
+			}
+			if child.IsDir {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+
+	sort.SliceStable(found, func(i, j int) bool { return size(found[i]) > size(found[j]) })
+	if len(found) > searchLimit {
+		return found[:searchLimit], true
+	}
+	return found, false
+}
+
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "nom, *.log, re:\\.bak$"
+	ti.CharLimit = 256
+	ti.Width = 50
+	return ti
+}
+
+// Ouvre la recherche en conservant la saisie précédente, relancée sur l'arbre actuel
+func (m Model) openSearch() (Model, tea.Cmd) {
+	m.searchInput.Focus()
+	m.searchInput.CursorEnd()
+	m.state = StateSearch
+	return m.runSearch(), textinput.Blink
+}
+
+// Recherche incrémentale : relancée à chaque modification de la saisie
+func (m Model) runSearch() Model {
+	m.searchResults, m.searchTruncated, m.searchErr = nil, false, nil
+	m.panel.reset()
+
+	query := strings.TrimSpace(m.searchInput.Value())
+	if query == "" {
+		return m
+	}
+	match, err := compileSearch(query)
+	if err != nil {
+		m.searchErr = err
+		return m
+	}
+	m.searchResults, m.searchTruncated = searchTree(m.root, match, m.sizeOf)
+	return m
+}
+
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.searchInput.Blur()
+		m.state = StateBrowsing
+		return m, nil
+	case "enter":
+		if m.panel.cursor < len(m.searchResults) {
+			m.searchInput.Blur()
+			n := m.searchResults[m.panel.cursor]
+			if n.Parent == nil {
+				m.currentNode, m.cursor, m.yOffset = n, 0, 0
+				m.state = StateBrowsing
+				return m, nil
+			}
+			return m.revealNode(n), nil
+		}
+		return m, nil
+	case "up", "down", "pgup", "pgdown":
+		m.panel.handleKey(msg.String(), len(m.searchResults), m.height-10)
+		return m, nil
+	}
+
+	previous := m.searchInput.Value()
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if m.searchInput.Value() != previous {
+		m = m.runSearch()
+	}
+	return m, cmd
+}
+
+func (m Model) viewSearch() string {
+	title := titleStyle.Render("AED - Recherche")
+	header := fmt.Sprintf("  %s  %s\n\n  %s", title, pathStyle.Render(m.root.Path), m.searchInput.View())
+
+	var content string
+	switch {
+	case m.searchErr != nil:
+		content = "  " + errorStyle.Render(m.searchErr.Error())
+	case strings.TrimSpace(m.searchInput.Value()) == "":
+		content = dimStyle.Render("  Texte contenu dans le nom, motif glob (sur le chemin s'il contient un /) ou regex préfixée par re:")
+	case len(m.searchResults) == 0:
+		content = "  Aucun résultat."
+	default:
+		rows := make([]string, len(m.searchResults))
+		for i, n := range m.searchResults {
+			name := n.Name
+			if n.IsDir {
+				name += "/"
+			}
+			rows[i] = fmt.Sprintf("%10s  %s  %s", formatBytes(m.sizeOf(n)), name, dimStyle.Render(filepath.Dir(n.Path)))
+		}
+		count := fmt.Sprintf("  %d résultat(s)", len(m.searchResults))
+		if m.searchTruncated {
+			count = fmt.Sprintf("  %d plus gros résultats affichés", searchLimit)
+		}
+		content = infoStyle.Render(count) + "\n" + m.panel.render(rows, m.height-10, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • enter: aller à l'élément • esc: retour")
+	return fmt.Sprintf("\n%s\n\n%s\n%s", header, content, footer)
+}
@@ -0,0 +1,378 @@
+package aeddsa
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Extension des instantanés de scan (gob compressé en gzip)
+const snapshotExt = ".aedsnap"
+
+// Version du format, incrémentée à chaque changement de la structure
+// Version 2 : droits (Mode) et taille propre des dossiers (OwnSize, OwnApparent)
+// Version 3 : liens physiques non comptés dans la taille des dossiers (Shared)
+const snapshotVersion = 3
+
+// Plus ancienne version relue : les champs ajoutés depuis y valent 0 (droits inconnus, taille propre nulle,
+// tous les liens considérés comme comptés)
+const snapshotMinVersion = 1
+
+// Contenu d'un instantané : l'arbre est stocké sans pointeur Parent (non sérialisable car cyclique)
+type snapshotFile struct {
+	Version    int
+	Root       string
+	Created    time.Time
+	Tree       snapshotNode
+	Exclusions []Exclusion
+}
+
+type snapshotNode struct {
+	Name        string
+	Size        int64
+	Apparent    int64
+	IsDir       bool
+	ModTime     time.Time
+	Atime       time.Time
+	Count       int64
+	Uid         uint32
+	Gid         uint32
+	Dev         uint64
+	Ino         uint64
+	Nlink       uint64
+	Mode        uint32
+	Shared      bool
+	ReadError   bool
+	ErrorMsg    string
+	OwnSize     int64
+	OwnApparent int64
+	Children    []snapshotNode
+}
+
+// Évolution de la taille d'un dossier entre deux instantanés
+type dirDelta struct {
+	Path    string
+	OldSize int64
+	NewSize int64
+	Delta   int64
+}
+
+// Message envoyé lorsque l'instantané à comparer est chargé
+type compareLoadedMsg struct {
+	root    *FileNode
+	created time.Time
+	err     error
+}
+
+var (
+	growStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF2A6D"))
+	shrinkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#39FF14"))
+)
+
+// Dossier par défaut des instantanés ($XDG_DATA_HOME/cyberTools/aed ou ~/.local/share/cyberTools/aed)
+func defaultSnapshotDir() string {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, "cyberTools", "aed")
+}
+
+// Préfixe des instantanés d'un dossier : chemin analysé aplati, suivi d'une empreinte du chemin exact
+// L'empreinte distingue les chemins qui s'aplatissent de la même façon (/a_b et /a/b)
+func snapshotPrefix(root string) string {
+	name := strings.Trim(strings.ReplaceAll(root, string(filepath.Separator), "_"), "_")
+	if name == "" {
+		name = "racine"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(root))
+	return fmt.Sprintf("%s-%08x-", name, h.Sum32())
+}
+
+// Nom de fichier par défaut : préfixe du dossier suivi de l'horodatage
+func defaultSnapshotPath(root string, at time.Time) string {
+	return filepath.Join(defaultSnapshotDir(), snapshotPrefix(root)+at.Format("20060102-150405")+snapshotExt)
+}
+
+// Indique si le chemin désigne un instantané
+func isSnapshot(path string) bool {
+	return strings.HasSuffix(path, snapshotExt)
+}
+
+// Écrit l'arbre dans un fichier compressé
+func saveSnapshot(path string, root *FileNode, exclusions []Exclusion, created time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	snap := snapshotFile{
+		Version:    snapshotVersion,
+		Root:       root.Path,
+		Created:    created,
+		Tree:       toSnapshotNode(root),
+		Exclusions: exclusions,
+	}
+	if err := gob.NewEncoder(zw).Encode(snap); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Relit un instantané et reconstruit l'arbre (chemins et pointeurs Parent)
+func loadSnapshot(path string) (*FileNode, []Exclusion, time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("%s n'est pas un instantané AED: %w", path, err)
+	}
+	defer zr.Close()
+
+	var snap snapshotFile
+	if err := gob.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("%s: instantané illisible: %w", path, err)
+	}
+	if snap.Version < snapshotMinVersion || snap.Version > snapshotVersion {
+		return nil, nil, time.Time{}, fmt.Errorf("%s: version d'instantané %d non supportée", path, snap.Version)
+	}
+
+	root := fromSnapshotNode(snap.Tree, snap.Root, nil)
+	root.Name = snap.Root
+	return root, snap.Exclusions, snap.Created, nil
+}
+
+func toSnapshotNode(n *FileNode) snapshotNode {
+	s := snapshotNode{
+		Name: n.Name, Size: n.Size, Apparent: n.Apparent, IsDir: n.IsDir, ModTime: n.ModTime,
+		Atime: n.AccessTime, Count: n.Count, Uid: n.Uid, Gid: n.Gid,
+		Dev: n.Dev, Ino: n.Ino, Nlink: n.Nlink, Mode: n.Mode, Shared: n.Shared, ReadError: n.ReadError, ErrorMsg: n.ErrorMsg,
+		OwnSize: n.OwnSize, OwnApparent: n.OwnApparent,
+	}
+	// Les entrées d'archive ouvertes pendant la navigation ne sont pas enregistrées
+	if !n.IsDir {
+		return s
+	}
+	for _, child := range n.Children {
+		s.Children = append(s.Children, toSnapshotNode(child))
+	}
+	return s
+}
+
+func fromSnapshotNode(s snapshotNode, path string, parent *FileNode) *FileNode {
+	node := &FileNode{
+		Name: s.Name, Path: path, Size: s.Size, Apparent: s.Apparent, IsDir: s.IsDir, ModTime: s.ModTime, Parent: parent,
+		AccessTime: s.Atime, Count: s.Count, Uid: s.Uid, Gid: s.Gid,
+		Dev: s.Dev, Ino: s.Ino, Nlink: s.Nlink, Mode: s.Mode, Shared: s.Shared, ReadError: s.ReadError, ErrorMsg: s.ErrorMsg,
+		OwnSize: s.OwnSize, OwnApparent: s.OwnApparent,
+	}
+	for _, child := range s.Children {
+		c := fromSnapshotNode(child, filepath.Join(path, child.Name), node)
+		node.Children = append(node.Children, c)
+		node.Unreadable += unreadable(c)
+		node.absorbTimes(c)
+	}
+	return node
+}
+
+// Commande Tea de chargement de l'instantané de référence pour la comparaison
+func loadCompareCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		root, _, created, err := loadSnapshot(path)
+		return compareLoadedMsg{root: root, created: created, err: err}
+	}
+}
+
+// Instantané le plus récent du même dossier, proposé par défaut pour la comparaison
+func latestSnapshotFor(root string, exclude time.Time) string {
+	matches, _ := filepath.Glob(filepath.Join(defaultSnapshotDir(), snapshotPrefix(root)+"*"+snapshotExt))
+	sort.Strings(matches)
+
+	for i := len(matches) - 1; i >= 0; i-- {
+		if matches[i] != defaultSnapshotPath(root, exclude) {
+			return matches[i]
+		}
+	}
+	return ""
+}
+
+// Compare les dossiers de deux arbres d'un même chemin, triés par variation absolue décroissante
+func diffTrees(oldRoot, newRoot *FileNode) []dirDelta {
+	oldSizes := dirSizes(oldRoot)
+	newSizes := dirSizes(newRoot)
+
+	var deltas []dirDelta
+	for rel, newSize := range newSizes {
+		oldSize := oldSizes[rel]
+		if newSize != oldSize {
+			deltas = append(deltas, dirDelta{Path: filepath.Join(newRoot.Path, rel), OldSize: oldSize, NewSize: newSize, Delta: newSize - oldSize})
+		}
+	}
+	for rel, oldSize := range oldSizes {
+		if _, ok := newSizes[rel]; !ok {
+			deltas = append(deltas, dirDelta{Path: filepath.Join(newRoot.Path, rel), OldSize: oldSize, Delta: -oldSize})
+		}
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		a, b := abs64(deltas[i].Delta), abs64(deltas[j].Delta)
+		if a != b {
+			return a > b
+		}
+		return deltas[i].Path < deltas[j].Path
+	})
+	return deltas
+}
+
+// Tailles des dossiers indexées par chemin relatif à la racine
+func dirSizes(root *FileNode) map[string]int64 {
+	sizes := make(map[string]int64)
+	var walk func(n *FileNode, rel string)
+	walk = func(n *FileNode, rel string) {
+		if !n.IsDir {
+			return
+		}
+		sizes[rel] = n.Size
+		for _, child := range n.Children {
+			walk(child, filepath.Join(rel, child.Name))
+		}
+	}
+	walk(root, ".")
+	return sizes
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Formate une variation de taille avec son signe
+func formatDelta(d int64) string {
+	if d < 0 {
+		return "-" + formatBytes(-d)
+	}
+	return "+" + formatBytes(d)
+}
+
+// Navigation dans la liste des variations ; enter ouvre le dossier dans l'arbre courant
+func (m Model) updateDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "backspace":
+		m.state = StateBrowsing
+		return m, nil
+	case "enter":
+		if m.panel.cursor < len(m.deltas) {
+			if node := findNode(m.root, m.deltas[m.panel.cursor].Path); node != nil && node.IsDir {
+				m.currentNode = node
+				m.cursor = 0
+				m.yOffset = 0
+				m.state = StateBrowsing
+			}
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(m.deltas), m.height-7)
+	return m, nil
+}
+
+// Vue des dossiers ayant grossi ou diminué entre les deux instantanés
+func (m Model) viewDiff() string {
+	title := titleStyle.Render("AED - Comparaison")
+	period := fmt.Sprintf("%s → %s", m.compareFrom.Format("02/01/2006 15:04"), m.compareTo.Format("02/01/2006 15:04"))
+	header := fmt.Sprintf("  %s  %s  %s\n", title, pathStyle.Render(m.root.Path), infoStyle.Render(period))
+
+	var content string
+	if len(m.deltas) == 0 {
+		content = "  Aucune variation de taille."
+	} else {
+		rows := make([]string, len(m.deltas))
+		for i, d := range m.deltas {
+			style := growStyle
+			if d.Delta < 0 {
+				style = shrinkStyle
+			}
+			rows[i] = fmt.Sprintf("%s  %s  %s",
+				style.Render(fmt.Sprintf("%10s", formatDelta(d.Delta))),
+				dimStyle.Render(fmt.Sprintf("%10s → %-10s", formatBytes(d.OldSize), formatBytes(d.NewSize))),
+				d.Path)
+		}
+		content = m.panel.render(rows, m.height-7, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • enter: ouvrir le dossier • esc: retour")
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
+
+// Enregistre l'arbre courant dans le dossier des instantanés
+func (m Model) saveCurrentSnapshot() Model {
+	path := defaultSnapshotPath(m.root.Path, m.scannedAt)
+	if err := saveSnapshot(path, m.root, m.exclusions, m.scannedAt); err != nil {
+		m.status = errorStyle.Render("Enregistrement impossible : " + err.Error())
+	} else {
+		m.status = "Instantané enregistré : " + path
+	}
+	return m
+}
+
+// Saisie de l'instantané de référence, pré-rempli avec le plus récent du même dossier
+func (m Model) openComparePrompt() (Model, tea.Cmd) {
+	return m.openPrompt("Comparer avec un instantané", "Instantané de référence", latestSnapshotFor(m.root.Path, m.scannedAt),
+		func(m Model, path string) (Model, tea.Cmd) {
+			m.status = "Chargement de " + path + "..."
+			return m, loadCompareCmd(path)
+		})
+}
+
+// Calcule les variations entre l'arbre courant et l'instantané chargé, le plus ancien servant de référence
+func (m Model) applyCompare(msg compareLoadedMsg) Model {
+	if msg.err != nil {
+		m.status = errorStyle.Render(msg.err.Error())
+		return m
+	}
+	if msg.root.Path != m.root.Path {
+		m.status = errorStyle.Render(fmt.Sprintf("L'instantané concerne %s et non %s", msg.root.Path, m.root.Path))
+		return m
+	}
+
+	oldRoot, newRoot := msg.root, m.root
+	m.compareFrom, m.compareTo = msg.created, m.scannedAt
+	if msg.created.After(m.scannedAt) {
+		oldRoot, newRoot = m.root, msg.root
+		m.compareFrom, m.compareTo = m.scannedAt, msg.created
+	}
+
+	m.deltas = diffTrees(oldRoot, newRoot)
+	m.status = ""
+	m.panel.reset()
+	m.state = StateDiff
+	return m
+}
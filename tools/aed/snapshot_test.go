@@ -0,0 +1,87 @@
+package aeddsa
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotPrefix(t *testing.T) {
+	roots := []string{"/", "/a/b", "/a_b", "/a/b/", "/var", "/var-log", "/var/log"}
+	seen := make(map[string]string)
+	for _, root := range roots {
+		p := snapshotPrefix(root)
+		if other, ok := seen[p]; ok {
+			t.Errorf("%s et %s partagent le préfixe %s", root, other, p)
+		}
+		seen[p] = root
+		// Aucun préfixe ne doit en couvrir un autre dans la recherche par motif de latestSnapshotFor
+		for q, r := range seen {
+			if q != p && (strings.HasPrefix(q, p) || strings.HasPrefix(p, q)) {
+				t.Errorf("%s (%s) et %s (%s) se chevauchent", root, p, r, q)
+			}
+		}
+	}
+	if got := snapshotPrefix("/"); !strings.HasPrefix(got, "racine-") {
+		t.Errorf("snapshotPrefix(/) = %q", got)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	root := testRoot("/data")
+	logs := testDir(root, "logs")
+	testFile(logs, "app.log", 4096)
+	root.OwnSize, logs.OwnSize = 4096, 4096
+
+	path := filepath.Join(t.TempDir(), "data"+snapshotExt)
+	if err := saveSnapshot(path, root, nil, testTime); err != nil {
+		t.Fatal(err)
+	}
+	got, _, created, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.Equal(testTime) || got.Size != root.Size || got.Mode != root.Mode || got.Children[0].OwnSize != 4096 {
+		t.Errorf("arbre relu différent : taille %d mode %o taille propre %d", got.Size, got.Mode, got.Children[0].OwnSize)
+	}
+}
+
+// Un instantané de version 1, sans droits ni taille propre, reste lisible
+func TestSnapshotVersion1(t *testing.T) {
+	type v1Node struct {
+		Name     string
+		Size     int64
+		IsDir    bool
+		Children []v1Node
+	}
+	type v1File struct {
+		Version int
+		Root    string
+		Tree    v1Node
+	}
+
+	path := filepath.Join(t.TempDir(), "v1"+snapshotExt)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	snap := v1File{Version: 1, Root: "/data", Tree: v1Node{Name: "/data", Size: 10, IsDir: true,
+		Children: []v1Node{{Name: "f", Size: 10}}}}
+	if err := gob.NewEncoder(zw).Encode(snap); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	f.Close()
+
+	root, _, _, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Size != 10 || root.Mode != 0 || root.Children[0].Path != "/data/f" {
+		t.Errorf("instantané v1 mal relu : taille %d mode %o chemin %s", root.Size, root.Mode, root.Children[0].Path)
+	}
+}
@@ -0,0 +1,467 @@
+package aeddsa
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Quirky1869/cyberTools/tools"
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// Variable remplacée par la version du noyau en cours d'exécution dans les motifs
+const kernelVar = "{{kernel}}"
+
+// Fichier de configuration d'AED (règles de nettoyage supplémentaires)
+type Config struct {
+	Suggestions []SuggestionRule `yaml:"suggestions"`
+}
+
+// Règle de nettoyage : les éléments dont le chemin correspond à un motif sont proposés à la suppression
+// Les motifs suivent la syntaxe des exclusions (glob sur le nom, sur le chemin s'il contient un /, ou "re:" pour une regex)
+type SuggestionRule struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Match       []string `yaml:"match"`
+	Keep        []string `yaml:"keep"`       // motifs à conserver malgré une correspondance
+	Type        string   `yaml:"type"`       // "dir", "file" ou vide pour les deux
+	OlderThan   int      `yaml:"older_than"` // jours depuis la dernière modification, 0 pour ignorer la date
+	Hint        string   `yaml:"hint"`       // commande de nettoyage conseillée
+	NoDelete    bool     `yaml:"no_delete"`  // nettoyage réservé à l'outil du conseil : ni marquage ni suppression depuis AED
+	Disabled    bool     `yaml:"disabled"`   // désactive une règle intégrée du même nom
+}
+
+// Règles intégrées, complétées ou remplacées (par nom) par celles de la configuration
+var builtinSuggestions = []SuggestionRule{
+	{
+		Name:        "Caches de paquets",
+		Description: "paquets téléchargés par le gestionnaire de paquets",
+		Match:       []string{`re:^/var/cache/(apt/archives|dnf|yum|pacman/pkg|zypp/packages)$`},
+		Type:        "dir",
+		Hint:        "apt-get clean / dnf clean all / paccache -r",
+		NoDelete:    true, // verrous et téléchargements partiels du gestionnaire
+	},
+	{
+		Name:        "node_modules",
+		Description: "dépendances npm réinstallables",
+		Match:       []string{"node_modules"},
+		Type:        "dir",
+		Hint:        "npm ci pour les réinstaller",
+	},
+	{
+		Name:        "Caches utilisateur",
+		Description: "dossiers .cache régénérés par les applications",
+		Match:       []string{".cache"},
+		Type:        "dir",
+	},
+	{
+		Name:        "Core dumps",
+		Description: "images mémoire de processus plantés",
+		Match:       []string{"core", "core.[0-9]*", `re:^/var/lib/systemd/coredump/`},
+		Type:        "file",
+		Hint:        "coredumpctl list",
+	},
+	{
+		Name:        "Journaux archivés",
+		Description: "journaux tournés et compressés par logrotate",
+		Match:       []string{"*.log.[0-9]*", `re:^/var/log/.*\.(gz|xz|bz2|zst|[0-9]+)$`},
+		Type:        "file",
+	},
+	{
+		Name:        "Anciens noyaux",
+		Description: "noyaux et modules autres que celui en cours d'exécution",
+		Match: []string{"/boot/vmlinuz-*", "/boot/initrd.img-*", "/boot/initramfs-*", "/boot/System.map-*", "/boot/config-*",
+			"/lib/modules/*", "/usr/lib/modules/*"},
+		Keep: []string{"*" + kernelVar + "*"},
+		Hint: "apt autoremove --purge / dnf remove --oldinstallonly",
+	},
+	{
+		Name:        "Couches Docker",
+		Description: "images, conteneurs et caches de build Docker",
+		Match:       []string{"/var/lib/docker/overlay2"},
+		Type:        "dir",
+		Hint:        "docker system prune -a",
+		NoDelete:    true, // supprimer overlay2 corrompt l'état de Docker
+	},
+}
+
+// Règle compilée
+type suggestRule struct {
+	SuggestionRule
+	match []excludeRule
+	keep  []excludeRule
+}
+
+// Éléments trouvés par une règle et espace récupérable
+type suggestion struct {
+	rule    *suggestRule
+	matches []*FileNode
+	usageStat
+}
+
+// Chemin par défaut du fichier de configuration d'AED
+func DefaultConfigPath() string {
+	dir := tools.ConfigDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "aed.yaml")
+}
+
+// Lit le fichier de configuration d'AED ; l'absence du fichier n'est pas une erreur
+func LoadConfig(path string) (*Config, error) {
+	var cfg Config
+	if path == "" {
+		return &cfg, nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, r := range cfg.Suggestions {
+		if r.Name == "" {
+			return nil, fmt.Errorf("%s: règle de nettoyage sans nom", path)
+		}
+		if len(r.Match) == 0 && !r.Disabled {
+			return nil, fmt.Errorf("%s: la règle %q n'a aucun motif", path, r.Name)
+		}
+		if r.Type != "" && r.Type != "dir" && r.Type != "file" {
+			return nil, fmt.Errorf("%s: type %q inconnu pour la règle %q (dir ou file)", path, r.Type, r.Name)
+		}
+	}
+	return &cfg, nil
+}
+
+// Fusionne les règles intégrées et celles de la configuration puis compile les motifs
+// Une règle utilisant {{kernel}} est ignorée si la version du noyau est inconnue
+func compileSuggestions(user []SuggestionRule, kernel string) ([]*suggestRule, error) {
+	rules := append([]SuggestionRule(nil), builtinSuggestions...)
+	for _, u := range user {
+		replaced := false
+		for i := range rules {
+			if rules[i].Name == u.Name {
+				rules[i], replaced = u, true
+				break
+			}
+		}
+		if !replaced {
+			rules = append(rules, u)
+		}
+	}
+
+	var compiled []*suggestRule
+	for _, r := range rules {
+		if r.Disabled {
+			continue
+		}
+		if kernel == "" && usesKernel(r) {
+			continue
+		}
+		c := &suggestRule{SuggestionRule: r}
+		for _, p := range r.Match {
+			rule, err := compileRule(strings.ReplaceAll(p, kernelVar, kernel))
+			if err != nil {
+				return nil, fmt.Errorf("règle %q: %w", r.Name, err)
+			}
+			c.match = append(c.match, rule)
+		}
+		for _, p := range r.Keep {
+			rule, err := compileRule(strings.ReplaceAll(p, kernelVar, kernel))
+			if err != nil {
+				return nil, fmt.Errorf("règle %q: %w", r.Name, err)
+			}
+			c.keep = append(c.keep, rule)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// Indique si un motif de la règle dépend de la version du noyau
+func usesKernel(r SuggestionRule) bool {
+	for _, p := range append(append([]string(nil), r.Match...), r.Keep...) {
+		if strings.Contains(p, kernelVar) {
+			return true
+		}
+	}
+	return false
+}
+
+// Version du noyau en cours d'exécution (vide si inconnue)
+func kernelRelease() string {
+	b, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// Indique si l'élément est concerné par la règle
+func (r *suggestRule) matches(n *FileNode, now time.Time) bool {
+	if (r.Type == "dir" && !n.IsDir) || (r.Type == "file" && n.IsDir) {
+		return false
+	}
+	if r.OlderThan > 0 && !n.lastModified().Before(now.AddDate(0, 0, -r.OlderThan)) {
+		return false
+	}
+	found := false
+	for _, p := range r.match {
+		if p.match(n.Path, n.Name) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	for _, p := range r.keep {
+		if p.match(n.Path, n.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// Applique les règles au sous-arbre : un élément revient à la première règle qui le concerne
+// et son contenu n'est pas examiné, pour que l'espace récupérable ne soit jamais compté deux fois
+func findSuggestions(root *FileNode, rules []*suggestRule, apparent bool, now time.Time) []suggestion {
+	stats := make([]suggestion, len(rules))
+	for i, r := range rules {
+		stats[i].rule = r
+	}
+
+	var walk func(n *FileNode)
+	walk = func(n *FileNode) {
+		for _, child := range n.Children {
+			if child.Virtual {
+				continue
+			}
+			matched := false
+			for i, r := range rules {
+				if r.matches(child, now) {
+					stats[i].matches = append(stats[i].matches, child)
+					stats[i].add(child)
+					matched = true
+					break
+				}
+			}
+			if !matched && child.IsDir {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+
+	var found []suggestion
+	for _, s := range stats {
+		if len(s.matches) == 0 {
+			continue
+		}
+		sort.Slice(s.matches, func(i, j int) bool {
+			if a, b := nodeSize(s.matches[i], apparent), nodeSize(s.matches[j], apparent); a != b {
+				return a > b
+			}
+			return s.matches[i].Path < s.matches[j].Path
+		})
+		found = append(found, s)
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].bytes(apparent) > found[j].bytes(apparent) })
+	return found
+}
+
+// Ouvre les suggestions de nettoyage du dossier courant ; la configuration est relue à chaque ouverture
+func (m Model) openSuggestions() Model {
+	m.suggestRoot = m.currentNode
+	m.suggestErr = nil
+	m.panel.reset()
+	m.state = StateSuggestions
+	return m.refreshSuggestions()
+}
+
+// Recalcule les suggestions (ouverture ou suppression d'éléments)
+func (m Model) refreshSuggestions() Model {
+	selected := ""
+	if m.suggestSel < len(m.suggestions) {
+		selected = m.suggestions[m.suggestSel].rule.Name
+	}
+	m.suggestions = nil
+	cfg, err := LoadConfig(DefaultConfigPath())
+	if err != nil {
+		m.suggestErr = err
+		cfg = &Config{}
+	}
+	rules, err := compileSuggestions(cfg.Suggestions, kernelRelease())
+	if err != nil {
+		m.suggestErr = err
+		rules, _ = compileSuggestions(nil, kernelRelease())
+	}
+	m.suggestions = findSuggestions(m.suggestRoot, rules, m.apparent, time.Now())
+
+	// La règle consultée n'a plus d'éléments : retour à la liste des règles
+	m.suggestSel = -1
+	for i, s := range m.suggestions {
+		if s.rule.Name == selected {
+			m.suggestSel = i
+		}
+	}
+	if m.suggestSel < 0 {
+		m.suggestSel = 0
+		if m.state == StateSuggestionMatches {
+			m.panel.reset()
+			m.state = StateSuggestions
+		}
+	}
+	return m
+}
+
+func (m Model) updateSuggestions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	switch msg.String() {
+	case "esc", "q", "n":
+		m.state = StateBrowsing
+		return m, nil
+	case "enter", "right", "l":
+		if m.panel.cursor < len(m.suggestions) {
+			m.suggestSel = m.panel.cursor
+			m.suggestPanel = m.panel
+			m.panel.reset()
+			m.state = StateSuggestionMatches
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(m.suggestions), m.height-9)
+	return m, nil
+}
+
+func (m Model) viewSuggestions() string {
+	title := titleStyle.Render("AED - Suggestions de nettoyage")
+	var total int64
+	for _, s := range m.suggestions {
+		total += s.bytes(m.apparent)
+	}
+	header := fmt.Sprintf("  %s  %s  (%s)\n", title, pathStyle.Render(m.suggestRoot.Path),
+		infoStyle.Render(fmt.Sprintf("%s récupérables", formatBytes(total))))
+
+	var content string
+	if len(m.suggestions) == 0 {
+		content = "  Aucune suggestion."
+	} else {
+		rows := make([]string, len(m.suggestions))
+		for i, s := range m.suggestions {
+			rows[i] = fmt.Sprintf("%-20.20s %8d  %10s  %s", s.rule.Name, s.count, formatBytes(s.bytes(m.apparent)),
+				dimStyle.Render(s.rule.Description))
+		}
+		content = dimStyle.Render(fmt.Sprintf("  %-20s %8s  %10s", "règle", "éléments", "taille")) + "\n" +
+			m.panel.render(rows, m.height-9, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • enter: éléments concernés • esc: retour")
+	if m.suggestErr != nil {
+		footer += "\n  " + errorStyle.Render(m.suggestErr.Error())
+	} else if path := DefaultConfigPath(); path != "" {
+		footer += "\n  " + dimStyle.Render("Règles supplémentaires : "+path)
+	}
+	if m.status != "" {
+		footer += "\n  " + m.status
+	}
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
+
+// Éléments trouvés par la règle choisie : marquage pour suppression et accès dans l'explorateur
+func (m Model) updateSuggestionMatches(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rule := m.suggestions[m.suggestSel].rule
+	matches := m.suggestions[m.suggestSel].matches
+	m.status = ""
+	switch msg.String() {
+	case "esc", "q", "backspace", "left", "h":
+		m.panel = m.suggestPanel
+		m.state = StateSuggestions
+		return m, nil
+	case " ", "a", "d", "delete":
+		if rule.NoDelete {
+			m.status = errorStyle.Render("Suppression refusée : ces éléments se nettoient avec l'outil dédié")
+			if rule.Hint != "" {
+				m.status = errorStyle.Render("Suppression refusée : utilisez " + rule.Hint)
+			}
+			return m, nil
+		}
+	}
+
+	switch msg.String() {
+	case " ":
+		if m.panel.cursor < len(matches) {
+			n := matches[m.panel.cursor]
+			if _, ok := m.marked[n.Path]; ok {
+				delete(m.marked, n.Path)
+			} else {
+				m.marked[n.Path] = n
+			}
+			m.panel.move(1, len(matches), m.height-8)
+		}
+		return m, nil
+	case "a":
+		for _, n := range matches {
+			m.marked[n.Path] = n
+		}
+		return m, nil
+	case "d", "delete":
+		if len(m.marked) == 0 {
+			m.status = errorStyle.Render("Aucun élément marqué")
+			return m, nil
+		}
+		return m.openDeleteConfirm(), nil
+	case "enter":
+		if m.panel.cursor < len(matches) {
+			return m.revealNode(matches[m.panel.cursor]), nil
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(matches), m.height-8)
+	return m, nil
+}
+
+func (m Model) viewSuggestionMatches() string {
+	s := m.suggestions[m.suggestSel]
+	title := titleStyle.Render("AED - " + s.rule.Name)
+	header := fmt.Sprintf("  %s  %s\n", title, infoStyle.Render(fmt.Sprintf("%d élément(s), %s", s.count, formatBytes(s.bytes(m.apparent)))))
+	if s.rule.Hint != "" {
+		header += "  " + dimStyle.Render("Conseil : "+s.rule.Hint) + "\n"
+	}
+
+	rows := make([]string, len(s.matches))
+	for i, n := range s.matches {
+		mark := " "
+		if _, ok := m.marked[n.Path]; ok {
+			mark = markStyle.Render("*")
+		}
+		name := n.Path
+		if n.IsDir {
+			name += "/"
+		}
+		rows[i] = fmt.Sprintf("%s %10s  %s", mark, formatBytes(nodeSize(n, m.apparent)), name)
+	}
+	content := m.panel.render(rows, m.height-9, m.width)
+
+	help := "\nespace: marquer • a: tout marquer • d: supprimer • enter: aller à l'élément • esc: retour"
+	if s.rule.NoDelete {
+		header += "  " + errorStyle.Render("Nettoyage uniquement via l'outil dédié : suppression directe désactivée") + "\n"
+		help = "\nenter: aller à l'élément • esc: retour"
+	}
+	footer := helpStyle.Render(help)
+	if m.status != "" {
+		footer += "\n  " + m.status
+	}
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
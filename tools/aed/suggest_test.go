@@ -0,0 +1,157 @@
+package aeddsa
+
+import (
+	"testing"
+	"time"
+)
+
+// Règle compilée à partir de son nom
+func ruleNamed(rules []*suggestRule, name string) *suggestRule {
+	for _, r := range rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+func TestCompileSuggestions(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    []SuggestionRule
+		kernel  string
+		present []string
+		absent  []string
+		count   int
+		wantErr bool
+	}{
+		{"intégrées", nil, "6.1.0", []string{"node_modules", "Anciens noyaux"}, nil, len(builtinSuggestions), false},
+		{"noyau inconnu", nil, "", []string{"node_modules"}, []string{"Anciens noyaux"}, len(builtinSuggestions) - 1, false},
+		{"désactivée", []SuggestionRule{{Name: "node_modules", Disabled: true}}, "6.1.0",
+			nil, []string{"node_modules"}, len(builtinSuggestions) - 1, false},
+		{"ajoutée", []SuggestionRule{{Name: "Builds", Match: []string{"target"}}}, "6.1.0",
+			[]string{"Builds"}, nil, len(builtinSuggestions) + 1, false},
+		{"remplacée", []SuggestionRule{{Name: "node_modules", Match: []string{"vendor"}}}, "6.1.0",
+			[]string{"node_modules"}, nil, len(builtinSuggestions), false},
+		{"motif invalide", []SuggestionRule{{Name: "x", Match: []string{"re:("}}}, "6.1.0", nil, nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := compileSuggestions(tt.user, tt.kernel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compileSuggestions erreur = %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if len(rules) != tt.count {
+				t.Errorf("%d règles, attendu %d", len(rules), tt.count)
+			}
+			for _, name := range tt.present {
+				if ruleNamed(rules, name) == nil {
+					t.Errorf("règle %q absente", name)
+				}
+			}
+			for _, name := range tt.absent {
+				if ruleNamed(rules, name) != nil {
+					t.Errorf("règle %q présente", name)
+				}
+			}
+		})
+	}
+
+	// Une règle remplacée garde sa position et prend les motifs de la configuration
+	rules, _ := compileSuggestions([]SuggestionRule{{Name: "node_modules", Match: []string{"vendor"}}}, "6.1.0")
+	r := ruleNamed(rules, "node_modules")
+	if len(r.match) != 1 || r.match[0].pattern != "vendor" || r.Type != "" {
+		t.Errorf("règle remplacée : %+v", r.SuggestionRule)
+	}
+}
+
+func TestFindSuggestions(t *testing.T) {
+	now := testTime.AddDate(0, 0, 30)
+	rules, err := compileSuggestions([]SuggestionRule{
+		{Name: "Vieux fichiers tmp", Match: []string{"*.tmp"}, Type: "file", OlderThan: 60},
+	}, "6.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := testRoot("/")
+	app := testDir(testDir(root, "src"), "app")
+	nm := testDir(app, "node_modules")
+	testFile(nm, "lib.js", 300)
+	// Un node_modules imbriqué n'est pas compté une seconde fois
+	testFile(testDir(nm, "node_modules"), "dep.js", 200)
+	testFile(app, "core", 1000)
+	testDir(app, "core.d")
+	tmp := testFile(app, "vieux.tmp", 10)
+	tmp.ModTime = testTime.AddDate(0, 0, -60)
+	testFile(app, "récent.tmp", 10)
+
+	boot := testDir(root, "boot")
+	testFile(boot, "vmlinuz-6.1.0", 8000)
+	testFile(boot, "vmlinuz-5.10.0", 7000)
+	testFile(boot, "config-5.10.0", 100)
+
+	found := findSuggestions(root, rules, false, now)
+	want := []struct {
+		rule  string
+		count int64
+		size  int64
+	}{
+		{"Anciens noyaux", 2, 7100},
+		{"Core dumps", 1, 1000},
+		{"node_modules", 1, 500},
+		{"Vieux fichiers tmp", 1, 10},
+	}
+	if len(found) != len(want) {
+		for _, s := range found {
+			t.Logf("%s : %d élément(s), %d octets", s.rule.Name, s.count, s.size)
+		}
+		t.Fatalf("%d règle(s) trouvée(s), attendu %d", len(found), len(want))
+	}
+	for i, w := range want {
+		s := found[i]
+		if s.rule.Name != w.rule || s.count != w.count || s.size != w.size {
+			t.Errorf("suggestion %d : %s (%d, %d octets), attendu %s (%d, %d octets)",
+				i, s.rule.Name, s.count, s.size, w.rule, w.count, w.size)
+		}
+	}
+	// Les éléments d'une règle sont triés par taille décroissante
+	if kernels := found[0].matches; kernels[0].Name != "vmlinuz-5.10.0" {
+		t.Errorf("premier élément %s, attendu vmlinuz-5.10.0", kernels[0].Name)
+	}
+}
+
+func TestSuggestRuleMatches(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	rules, err := compileSuggestions([]SuggestionRule{
+		{Name: "r", Match: []string{"*.bak", "re:^/var/backups/"}, Keep: []string{"garder.bak"}, Type: "file", OlderThan: 7},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := ruleNamed(rules, "r")
+
+	old := now.AddDate(0, 0, -30)
+	tests := []struct {
+		name string
+		node *FileNode
+		want bool
+	}{
+		{"motif sur le nom", &FileNode{Name: "a.bak", Path: "/home/a.bak", ModTime: old}, true},
+		{"regex sur le chemin", &FileNode{Name: "x", Path: "/var/backups/x", ModTime: old}, true},
+		{"conservé", &FileNode{Name: "garder.bak", Path: "/home/garder.bak", ModTime: old}, false},
+		{"trop récent", &FileNode{Name: "a.bak", Path: "/home/a.bak", ModTime: now.AddDate(0, 0, -1)}, false},
+		{"dossier", &FileNode{Name: "d.bak", Path: "/home/d.bak", IsDir: true, ModTime: old}, false},
+		{"sans correspondance", &FileNode{Name: "a.txt", Path: "/home/a.txt", ModTime: old}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.matches(tt.node, now); got != tt.want {
+				t.Errorf("matches(%s) = %v, attendu %v", tt.node.Path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package aeddsa
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Corbeille freedesktop.org : dossier files/ pour les éléments et info/ pour leur fiche .trashinfo
+type trashDir struct {
+	root string
+	// Corbeille d'un autre système de fichiers : chemins d'origine relatifs à son point de montage
+	topdir string
+}
+
+// Corbeille de l'utilisateur ($XDG_DATA_HOME/Trash ou ~/.local/share/Trash)
+func homeTrash() (trashDir, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return trashDir{}, err
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return trashDir{root: filepath.Join(base, "Trash")}, nil
+}
+
+// Corbeille du point de montage contenant path ($topdir/.Trash-$uid)
+func topdirTrash(path string) (trashDir, error) {
+	mounts, err := readMountInfo("/proc/self/mountinfo")
+	if err != nil {
+		return trashDir{}, err
+	}
+	top := "/"
+	for mnt := range mounts {
+		if len(mnt) > len(top) && (path == mnt || strings.HasPrefix(path, mnt+"/")) {
+			top = mnt
+		}
+	}
+	return trashDir{root: filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid())), topdir: top}, nil
+}
+
+// Déplace un fichier ou dossier dans la corbeille, celle du système de fichiers concerné si le renommage est impossible
+func moveToTrash(path string) error {
+	trash, err := homeTrash()
+	if err != nil {
+		return err
+	}
+	err = trash.put(path)
+	if errors.Is(err, syscall.EXDEV) {
+		if trash, err = topdirTrash(path); err != nil {
+			return err
+		}
+		err = trash.put(path)
+	}
+	return err
+}
+
+// Crée la fiche .trashinfo sous un nom libre puis renomme l'élément dans files/
+func (t trashDir) put(path string) error {
+	filesDir := filepath.Join(t.root, "files")
+	infoDir := filepath.Join(t.root, "info")
+	if err := os.MkdirAll(filesDir, 0o700); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(infoDir, 0o700); err != nil {
+		return err
+	}
+
+	origPath := path
+	if t.topdir != "" {
+		if rel, err := filepath.Rel(t.topdir, path); err == nil {
+			origPath = rel
+		}
+	}
+	// Chemin encodé comme une URL, séparateurs conservés
+	escaped := (&url.URL{Path: origPath}).EscapedPath()
+	content := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", escaped, time.Now().Format("2006-01-02T15:04:05"))
+
+	base := filepath.Base(path)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d", base, i)
+		}
+
+		// O_EXCL réserve le nom de façon atomique face aux autres programmes
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		// Nom déjà pris dans files/ sans fiche associée : on n'écrase rien
+		if _, err := os.Lstat(filepath.Join(filesDir, name)); err == nil {
+			f.Close()
+			os.Remove(infoPath)
+			continue
+		}
+		_, err = f.WriteString(content)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return err
+		}
+
+		if err := os.Rename(path, filepath.Join(filesDir, name)); err != nil {
+			os.Remove(infoPath)
+			return err
+		}
+		return nil
+	}
+}
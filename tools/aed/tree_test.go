@@ -0,0 +1,39 @@
+package aeddsa
+
+import (
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Date de modification commune des arbres de test
+var testTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// Racine d'un arbre de test
+func testRoot(path string) *FileNode {
+	return &FileNode{Name: path, Path: path, IsDir: true, Mode: syscall.S_IFDIR | 0o755, ModTime: testTime}
+}
+
+// Ajoute un sous-dossier vide
+func testDir(parent *FileNode, name string) *FileNode {
+	n := &FileNode{Name: name, Path: filepath.Join(parent.Path, name), IsDir: true, Parent: parent,
+		Mode: syscall.S_IFDIR | 0o755, ModTime: testTime}
+	parent.Children = append(parent.Children, n)
+	for p := parent; p != nil; p = p.Parent {
+		p.Count++
+	}
+	return n
+}
+
+// Ajoute un fichier régulier et répercute sa taille sur les dossiers parents
+func testFile(parent *FileNode, name string, size int64) *FileNode {
+	n := &FileNode{Name: name, Path: filepath.Join(parent.Path, name), Size: size, Apparent: size, Parent: parent,
+		Mode: syscall.S_IFREG | 0o644, ModTime: testTime, Nlink: 1}
+	parent.Children = append(parent.Children, n)
+	for p := parent; p != nil; p = p.Parent {
+		p.Size += size
+		p.Apparent += size
+		p.Count++
+	}
+	return n
+}
@@ -0,0 +1,394 @@
+package aeddsa
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Position de la treemap dans la vue (marge gauche et lignes d'en-tête)
+const (
+	treemapLeft = 2
+	treemapTop  = 3
+)
+
+// Rectangle de la treemap, en cellules du terminal
+type tmRect struct {
+	node       *FileNode
+	x, y, w, h int
+}
+
+type frect struct {
+	x, y, w, h float64
+}
+
+// Tranches d'âge pour la coloration par date de modification
+var ageBuckets = []struct {
+	label string
+	max   time.Duration
+	color lipgloss.Color
+}{
+	{"< 1 jour", 24 * time.Hour, "#FF2A6D"},
+	{"< 1 semaine", 7 * 24 * time.Hour, "#ff00d4"},
+	{"< 1 mois", 30 * 24 * time.Hour, "#7B61FF"},
+	{"< 1 an", 365 * 24 * time.Hour, "#00f6ff"},
+	{"> 1 an", math.MaxInt64, "#2E6F80"},
+}
+
+var (
+	tmTextStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#111111"))
+	tmSelectedStyle = lipgloss.NewStyle().Background(lipgloss.Color("#ffffff")).Foreground(lipgloss.Color("#000000")).Bold(true)
+)
+
+// Dimensions de la zone de dessin
+func (m Model) treemapSize() (int, int) {
+	return max(m.width-treemapLeft*2, 1), max(m.height-8, 1)
+}
+
+// Calcule la disposition des enfants du dossier courant
+func (m Model) treemapRects() []tmRect {
+	w, h := m.treemapSize()
+
+	var nodes []*FileNode
+	var sizes []float64
+	for _, child := range m.currentNode.Children {
+		if m.sizeOf(child) > 0 {
+			nodes = append(nodes, child)
+			sizes = append(sizes, float64(m.sizeOf(child)))
+		}
+	}
+
+	// L'algorithme attend des tailles décroissantes, quel que soit le tri de l'explorateur
+	sort.SliceStable(nodes, func(i, j int) bool { return m.sizeOf(nodes[i]) > m.sizeOf(nodes[j]) })
+	for i, n := range nodes {
+		sizes[i] = float64(m.sizeOf(n))
+	}
+
+	// Une cellule est environ deux fois plus haute que large : on dispose sur une hauteur doublée
+	layout := squarify(sizes, frect{0, 0, float64(w), float64(h) * 2})
+
+	var rects []tmRect
+	for i, r := range layout {
+		x0, x1 := int(math.Round(r.x)), int(math.Round(r.x+r.w))
+		y0, y1 := int(math.Round(r.y/2)), int(math.Round((r.y+r.h)/2))
+		if x1 > x0 && y1 > y0 {
+			rects = append(rects, tmRect{node: nodes[i], x: x0, y: y0, w: x1 - x0, h: y1 - y0})
+		}
+	}
+	return rects
+}
+
+// Algorithme "squarified" (Bruls, Huizing, van Wijk) : tailles triées par ordre décroissant
+func squarify(sizes []float64, r frect) []frect {
+	out := make([]frect, len(sizes))
+	var total float64
+	for _, s := range sizes {
+		total += s
+	}
+	if total == 0 || r.w <= 0 || r.h <= 0 {
+		return out
+	}
+
+	areas := make([]float64, len(sizes))
+	for i, s := range sizes {
+		areas[i] = s * r.w * r.h / total
+	}
+
+	for i := 0; i < len(areas); {
+		short := math.Min(r.w, r.h)
+		j := i + 1
+		for j < len(areas) && worstRatio(areas[i:j+1], short) <= worstRatio(areas[i:j], short) {
+			j++
+		}
+
+		var rowSum float64
+		for _, a := range areas[i:j] {
+			rowSum += a
+		}
+
+		if r.w >= r.h {
+			colW := rowSum / r.h
+			y := r.y
+			for k := i; k < j; k++ {
+				out[k] = frect{r.x, y, colW, areas[k] / colW}
+				y += areas[k] / colW
+			}
+			r.x += colW
+			r.w -= colW
+		} else {
+			rowH := rowSum / r.w
+			x := r.x
+			for k := i; k < j; k++ {
+				out[k] = frect{x, r.y, areas[k] / rowH, rowH}
+				x += areas[k] / rowH
+			}
+			r.y += rowH
+			r.h -= rowH
+		}
+		i = j
+	}
+	return out
+}
+
+// Pire rapport largeur/hauteur d'une rangée posée sur un côté de longueur side
+func worstRatio(row []float64, side float64) float64 {
+	var sum, lo, hi float64
+	lo = math.MaxFloat64
+	for _, a := range row {
+		sum += a
+		lo = math.Min(lo, a)
+		hi = math.Max(hi, a)
+	}
+	s2, w2 := sum*sum, side*side
+	return math.Max(w2*hi/s2, s2/(w2*lo))
+}
+
+// Couleur d'un rectangle selon le mode (type ou âge)
+func (m Model) treemapColor(n *FileNode) lipgloss.Color {
+	if m.tmByAge {
+		age := time.Since(n.lastModified())
+		for _, b := range ageBuckets {
+			if age < b.max {
+				return b.color
+			}
+		}
+	}
+	return kindColors[fileKind(n)]
+}
+
+// Rectangle situé sous une cellule de la zone de dessin
+func rectAt(rects []tmRect, x, y int) int {
+	for i, r := range rects {
+		if x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h {
+			return i
+		}
+	}
+	return -1
+}
+
+// Rectangle voisin le plus proche dans la direction (dx, dy)
+func neighbourRect(rects []tmRect, from, dx, dy int) int {
+	if from < 0 || from >= len(rects) {
+		return 0
+	}
+	cx := float64(rects[from].x) + float64(rects[from].w)/2
+	cy := (float64(rects[from].y) + float64(rects[from].h)/2) * 2
+
+	best, bestScore := from, math.MaxFloat64
+	for i, r := range rects {
+		if i == from {
+			continue
+		}
+		ox := float64(r.x) + float64(r.w)/2 - cx
+		oy := (float64(r.y)+float64(r.h)/2)*2 - cy
+		along, across := ox*float64(dx)+oy*float64(dy), math.Abs(ox*float64(dy))+math.Abs(oy*float64(dx))
+		if along <= 0 {
+			continue
+		}
+		if score := along + 2*across; score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// Zoom sur un dossier de la treemap
+func (m Model) treemapZoomIn(i int, rects []tmRect) Model {
+	if i >= 0 && i < len(rects) && rects[i].node.IsDir {
+		m.currentNode = rects[i].node
+		m.tmCursor = 0
+		m.cursor, m.yOffset = 0, 0
+	}
+	return m
+}
+
+// Retour au dossier parent, le dossier quitté restant sélectionné
+func (m Model) treemapZoomOut() Model {
+	if m.currentNode.Parent == nil {
+		return m
+	}
+	from := m.currentNode
+	m.currentNode = from.Parent
+	m.cursor, m.yOffset = 0, 0
+	m.tmCursor = 0
+	for i, r := range m.treemapRects() {
+		if r.node == from {
+			m.tmCursor = i
+		}
+	}
+	return m
+}
+
+func (m Model) updateTreemap(msg tea.Msg) (tea.Model, tea.Cmd) {
+	rects := m.treemapRects()
+
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonLeft:
+			i := rectAt(rects, msg.X-treemapLeft, msg.Y-treemapTop)
+			if i < 0 {
+				return m, nil
+			}
+			// Un second clic sur le rectangle sélectionné entre dans le dossier
+			if i == m.tmCursor {
+				return m.treemapZoomIn(i, rects), nil
+			}
+			m.tmCursor = i
+		case tea.MouseButtonRight, tea.MouseButtonWheelUp:
+			return m.treemapZoomOut(), nil
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "m", "q":
+			m.state = StateBrowsing
+		case "t":
+			m.tmByAge = !m.tmByAge
+		case "enter":
+			return m.treemapZoomIn(m.tmCursor, rects), nil
+		case "backspace":
+			return m.treemapZoomOut(), nil
+		case "left", "h":
+			m.tmCursor = neighbourRect(rects, m.tmCursor, -1, 0)
+		case "right", "l":
+			m.tmCursor = neighbourRect(rects, m.tmCursor, 1, 0)
+		case "up", "k":
+			m.tmCursor = neighbourRect(rects, m.tmCursor, 0, -1)
+		case "down", "j":
+			m.tmCursor = neighbourRect(rects, m.tmCursor, 0, 1)
+		case "tab":
+			if len(rects) > 0 {
+				m.tmCursor = (m.tmCursor + 1) % len(rects)
+			}
+		}
+	}
+	return m, nil
+}
+
+// Vue treemap : chaque rectangle a une aire proportionnelle à la taille de l'élément
+func (m Model) viewTreemap() string {
+	title := titleStyle.Render("AED - Treemap")
+	header := fmt.Sprintf("  %s  %s  (%s)\n", title, pathStyle.Render(m.currentNode.Path),
+		infoStyle.Render("Total: "+formatBytes(m.currentTotal())+" "+m.sizeLabel()))
+
+	rects := m.treemapRects()
+	w, h := m.treemapSize()
+
+	var body string
+	if len(rects) == 0 {
+		body = "  Dossier vide."
+	} else {
+		body = m.renderTreemap(rects, w, h)
+	}
+
+	info := ""
+	if m.tmCursor < len(rects) {
+		sel := rects[m.tmCursor].node
+		name := sel.Name
+		if sel.IsDir {
+			name += "/"
+		}
+		percent := 0.0
+		if total := m.currentTotal(); total > 0 {
+			percent = float64(m.sizeOf(sel)) / float64(total) * 100
+		}
+		info = fmt.Sprintf("  %s  %s  %.1f%%  %s%s", pathStyle.Render(name), formatBytes(m.sizeOf(sel)), percent,
+			dimStyle.Render(sel.lastModified().Format("02/01/2006")), m.sparseTag(sel))
+	}
+
+	footer := helpStyle.Render("\n↑/↓/←/→: sélectionner • enter/clic: entrer • backspace/clic droit: remonter • t: type/âge • esc: liste")
+	return fmt.Sprintf("\n%s\n%s\n%s\n%s%s", header, body, info, m.treemapLegend(), footer)
+}
+
+// Dessine les rectangles ligne par ligne ; une colonne et une ligne vides séparent les rectangles
+func (m Model) renderTreemap(rects []tmRect, w, h int) string {
+	owner := make([][]int, h)
+	text := make([][]rune, h)
+	for y := range owner {
+		owner[y] = make([]int, w)
+		text[y] = []rune(strings.Repeat(" ", w))
+		for x := range owner[y] {
+			owner[y][x] = -1
+		}
+	}
+
+	for i, r := range rects {
+		rw, rh := r.w, r.h
+		if rw > 2 {
+			rw--
+		}
+		if rh > 2 {
+			rh--
+		}
+		for y := r.y; y < r.y+rh && y < h; y++ {
+			for x := r.x; x < r.x+rw && x < w; x++ {
+				owner[y][x] = i
+			}
+		}
+
+		name := r.node.Name
+		if r.node.IsDir {
+			name += "/"
+		}
+		for li, label := range []string{name, formatBytes(m.sizeOf(r.node))} {
+			if li >= rh || r.y+li >= h {
+				break
+			}
+			runes := []rune(label)
+			if len(runes) > rw {
+				runes = runes[:rw]
+			}
+			copy(text[r.y+li][r.x:], runes)
+		}
+	}
+
+	lines := make([]string, h)
+	for y := 0; y < h; y++ {
+		var b strings.Builder
+		b.WriteString(strings.Repeat(" ", treemapLeft))
+		for x := 0; x < w; {
+			o := owner[y][x]
+			end := x
+			for end < w && owner[y][end] == o {
+				end++
+			}
+			segment := string(text[y][x:end])
+			switch {
+			case o < 0:
+				b.WriteString(strings.Repeat(" ", end-x))
+			case o == m.tmCursor:
+				b.WriteString(tmSelectedStyle.Render(segment))
+			default:
+				b.WriteString(tmTextStyle.Background(m.treemapColor(rects[o].node)).Render(segment))
+			}
+			x = end
+		}
+		lines[y] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Légende des couleurs du mode courant
+func (m Model) treemapLegend() string {
+	var parts []string
+	if m.tmByAge {
+		for _, b := range ageBuckets {
+			parts = append(parts, lipgloss.NewStyle().Foreground(b.color).Render("■")+" "+b.label)
+		}
+	} else {
+		for _, kind := range fileKinds {
+			parts = append(parts, lipgloss.NewStyle().Foreground(kindColors[kind]).Render("■")+" "+kind)
+		}
+	}
+	return "  " + strings.Join(parts, "  ")
+}
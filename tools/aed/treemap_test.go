@@ -0,0 +1,64 @@
+package aeddsa
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSquarify(t *testing.T) {
+	const eps = 1e-6
+	tests := []struct {
+		name  string
+		sizes []float64
+		r     frect
+	}{
+		{"un élément", []float64{10}, frect{0, 0, 80, 40}},
+		{"exemple de l'article", []float64{6, 6, 4, 3, 2, 2, 1}, frect{0, 0, 6, 4}},
+		{"décalé", []float64{50, 30, 20}, frect{2, 3, 40, 100}},
+		{"très inégal", []float64{1000, 1, 1, 1}, frect{0, 0, 120, 60}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := squarify(tt.sizes, tt.r)
+			if len(out) != len(tt.sizes) {
+				t.Fatalf("%d rectangles pour %d tailles", len(out), len(tt.sizes))
+			}
+
+			var total float64
+			for _, s := range tt.sizes {
+				total += s
+			}
+			for i, o := range out {
+				// Surface proportionnelle à la taille
+				want := tt.sizes[i] / total * tt.r.w * tt.r.h
+				if math.Abs(o.w*o.h-want) > eps*want+eps {
+					t.Errorf("rectangle %d : surface %.3f, attendu %.3f", i, o.w*o.h, want)
+				}
+				// Entièrement dans la zone
+				if o.x < tt.r.x-eps || o.y < tt.r.y-eps || o.x+o.w > tt.r.x+tt.r.w+eps || o.y+o.h > tt.r.y+tt.r.h+eps {
+					t.Errorf("rectangle %d hors de la zone : %+v", i, o)
+				}
+				// Sans chevauchement
+				for j, p := range out[:i] {
+					if o.x+eps < p.x+p.w && p.x+eps < o.x+o.w && o.y+eps < p.y+p.h && p.y+eps < o.y+o.h {
+						t.Errorf("rectangles %d et %d se chevauchent : %+v %+v", j, i, p, o)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSquarifyEmpty(t *testing.T) {
+	for _, out := range [][]frect{
+		squarify(nil, frect{0, 0, 10, 10}),
+		squarify([]float64{0, 0}, frect{0, 0, 10, 10}),
+		squarify([]float64{1}, frect{0, 0, 0, 10}),
+	} {
+		for _, o := range out {
+			if o != (frect{}) {
+				t.Errorf("rectangle inattendu %+v", o)
+			}
+		}
+	}
+}
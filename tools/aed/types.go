@@ -0,0 +1,383 @@
+package aeddsa
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Taille lue en début de fichier pour reconnaître son type (limite de http.DetectContentType)
+const sniffSize = 512
+
+// Regroupement de la vue des types
+const (
+	groupByExt = iota
+	groupByMime
+)
+
+// Fichiers partageant la même extension ou le même type MIME
+type typeStat struct {
+	key   string
+	kind  string
+	size  int64
+	files []*FileNode // du plus gros au plus petit
+}
+
+// Message envoyé lorsque le contenu des fichiers a été analysé
+type mimeFoundMsg struct {
+	done  *atomic.Int64 // compteur de l'analyse, qui l'identifie
+	stats []typeStat
+	err   error
+}
+
+// Signatures absentes de http.DetectContentType
+var extraMagic = []struct {
+	offset int
+	magic  []byte
+	mime   string
+}{
+	{0, []byte("\x7fELF"), "application/x-executable"},
+	{0, []byte("7z\xbc\xaf\x27\x1c"), "application/x-7z-compressed"},
+	{0, []byte("\xfd7zXZ\x00"), "application/x-xz"},
+	{0, []byte("\x28\xb5\x2f\xfd"), "application/zstd"},
+	{0, []byte("BZh"), "application/x-bzip2"},
+	{0, []byte("SQLite format 3\x00"), "application/vnd.sqlite3"},
+	{0, []byte("QFI\xfb"), "application/x-qemu-disk"},
+	{257, []byte("ustar"), "application/x-tar"},
+	{4, []byte("ftypqt"), "video/quicktime"},
+	{4, []byte("ftypheic"), "image/heic"},
+	{0, []byte("fLaC"), "audio/flac"},
+}
+
+// Catégorie d'affichage d'un type MIME
+func mimeKind(mimeType string) string {
+	top, sub, _ := strings.Cut(mimeType, "/")
+	switch top {
+	case "image":
+		return kindImage
+	case "video":
+		return kindVideo
+	case "audio":
+		return kindAudio
+	case "text":
+		return kindDocument
+	}
+	switch {
+	case strings.Contains(sub, "zip"), strings.Contains(sub, "compressed"), strings.Contains(sub, "tar"),
+		sub == "x-xz", sub == "zstd", sub == "x-bzip2", sub == "vnd.rar":
+		return kindArchive
+	case sub == "pdf", sub == "postscript", strings.HasPrefix(sub, "vnd.openxmlformats"), strings.HasPrefix(sub, "vnd.oasis"):
+		return kindDocument
+	case sub == "x-qemu-disk":
+		return kindDisk
+	}
+	return kindOther
+}
+
+// Type MIME d'un fichier d'après ses premiers octets
+func sniffFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	buf = buf[:n]
+	if n == 0 {
+		return "inode/x-empty", nil
+	}
+
+	for _, s := range extraMagic {
+		if len(buf) >= s.offset+len(s.magic) && bytes.Equal(buf[s.offset:s.offset+len(s.magic)], s.magic) {
+			return s.mime, nil
+		}
+	}
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(buf))
+	return mimeType, nil
+}
+
+// Fichiers du sous-arbre, en ne comptant qu'une fois chaque inode
+func subtreeFiles(root *FileNode) []*FileNode {
+	var files []*FileNode
+	seen := make(map[fileID]struct{})
+
+	var walk func(n *FileNode)
+	walk = func(n *FileNode) {
+		for _, child := range n.Children {
+			if child.IsDir {
+				walk(child)
+				continue
+			}
+			if child.Ino != 0 {
+				id := fileID{dev: child.Dev, ino: child.Ino}
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+			}
+			files = append(files, child)
+		}
+	}
+	walk(root)
+	return files
+}
+
+// Regroupe les fichiers par clé et trie les groupes, puis leurs fichiers, par taille décroissante
+func groupFiles(files []*FileNode, keyOf func(*FileNode) string, kindOf func(*FileNode, string) string, apparent bool) []typeStat {
+	index := make(map[string]int)
+	var stats []typeStat
+	for _, f := range files {
+		key := keyOf(f)
+		i, ok := index[key]
+		if !ok {
+			i = len(stats)
+			index[key] = i
+			stats = append(stats, typeStat{key: key, kind: kindOf(f, key)})
+		}
+		stats[i].size += nodeSize(f, apparent)
+		stats[i].files = append(stats[i].files, f)
+	}
+
+	for _, s := range stats {
+		sort.SliceStable(s.files, func(i, j int) bool { return nodeSize(s.files[i], apparent) > nodeSize(s.files[j], apparent) })
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].size != stats[j].size {
+			return stats[i].size > stats[j].size
+		}
+		return stats[i].key < stats[j].key
+	})
+	return stats
+}
+
+// Regroupement immédiat par extension
+func statsByExt(files []*FileNode, apparent bool) []typeStat {
+	return groupFiles(files, func(f *FileNode) string {
+		if ext := strings.ToLower(filepath.Ext(f.Name)); ext != "" {
+			return ext
+		}
+		return "(sans extension)"
+	}, func(f *FileNode, _ string) string {
+		return fileKind(f)
+	}, apparent)
+}
+
+// Commande Tea d'analyse du contenu des fichiers en arrière-plan
+func sniffTypesCmd(ctx context.Context, files []*FileNode, apparent bool, done *atomic.Int64) tea.Cmd {
+	return func() tea.Msg {
+		stats, err := statsByMime(ctx, files, apparent, done)
+		return mimeFoundMsg{done: done, stats: stats, err: err}
+	}
+}
+
+// Regroupement par type MIME détecté sur les premiers octets, lus en parallèle
+// Les fichiers illisibles sont regroupés sous "inconnu"
+func statsByMime(ctx context.Context, files []*FileNode, apparent bool, done *atomic.Int64) ([]typeStat, error) {
+	types := make([]string, len(files))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, scanWorkers)
+
+	for i, f := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			mimeType, err := sniffFile(path)
+			if err != nil {
+				mimeType = "inconnu"
+			}
+			types[i] = mimeType
+			done.Add(1)
+		}(i, f.Path)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	byNode := make(map[*FileNode]string, len(files))
+	for i, f := range files {
+		byNode[f] = types[i]
+	}
+	return groupFiles(files, func(f *FileNode) string {
+		return byNode[f]
+	}, func(_ *FileNode, key string) string {
+		return mimeKind(key)
+	}, apparent), nil
+}
+
+// Ouvre la répartition par type du dossier courant et lance l'analyse du contenu
+func (m Model) startTypes() (Model, tea.Cmd) {
+	m.cancelTypes()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.typeCancel = cancel
+	m.typeRoot = m.currentNode
+	m.typeFiles = subtreeFiles(m.currentNode)
+	m.typeByExt = statsByExt(m.typeFiles, m.apparent)
+	m.typeByMime = nil
+	m.typeSniffed = &atomic.Int64{}
+	m.typeGroup = groupByExt
+	m.panel.reset()
+	m.state = StateTypes
+
+	return m, tea.Batch(m.spinner.Tick, sniffTypesCmd(ctx, m.typeFiles, m.apparent, m.typeSniffed))
+}
+
+func (m *Model) cancelTypes() {
+	if m.typeCancel != nil {
+		m.typeCancel()
+		m.typeCancel = nil
+	}
+}
+
+// Reçoit le regroupement par type MIME, ignoré s'il concerne une analyse précédente
+func (m Model) applyMime(msg mimeFoundMsg) Model {
+	if msg.done != m.typeSniffed {
+		return m
+	}
+	m.cancelTypes()
+	if msg.err == nil {
+		m.typeByMime = msg.stats
+	}
+	return m
+}
+
+// Groupes affichés selon le regroupement choisi
+func (m Model) typeStats() []typeStat {
+	if m.typeGroup == groupByMime {
+		return m.typeByMime
+	}
+	return m.typeByExt
+}
+
+func (m Model) updateTypes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	stats := m.typeStats()
+	switch msg.String() {
+	case "esc", "q", "t":
+		m.cancelTypes()
+		m.state = StateBrowsing
+		return m, nil
+	case "tab":
+		m.typeGroup = 1 - m.typeGroup
+		m.panel.reset()
+		return m, nil
+	case "enter", "right", "l":
+		if m.panel.cursor < len(stats) {
+			m.typeSel = m.panel.cursor
+			m.typePanel = m.panel
+			m.panel.reset()
+			m.state = StateTypeFiles
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(stats), m.height-8)
+	return m, nil
+}
+
+func (m Model) viewTypes() string {
+	title := titleStyle.Render("AED - Types de fichiers")
+	tabs := []string{"extension", "type MIME"}
+	tabs[m.typeGroup] = pathStyle.Render("[" + tabs[m.typeGroup] + "]")
+	header := fmt.Sprintf("  %s  %s  (%s)  %s\n", title, pathStyle.Render(m.typeRoot.Path),
+		infoStyle.Render(fmt.Sprintf("%d fichiers", len(m.typeFiles))), strings.Join(tabs, " "))
+
+	var content string
+	stats := m.typeStats()
+	switch {
+	case m.typeGroup == groupByMime && m.typeCancel != nil:
+		content = fmt.Sprintf("  %s Analyse du contenu des fichiers... %s",
+			m.spinner.View(), countStyle.Render(fmt.Sprintf("%d / %d", m.typeSniffed.Load(), len(m.typeFiles))))
+	case len(stats) == 0:
+		content = "  Aucun fichier."
+	default:
+		total := m.sizeOf(m.typeRoot)
+		rows := make([]string, len(stats))
+		for i, s := range stats {
+			percent := 0.0
+			if total > 0 {
+				percent = float64(s.size) / float64(total) * 100
+			}
+			rows[i] = fmt.Sprintf("%s %-28.28s %8d  %10s  %5.1f%%",
+				lipgloss.NewStyle().Foreground(kindColors[s.kind]).Render("■"), s.key, len(s.files), formatBytes(s.size), percent)
+		}
+		content = m.panel.render(rows, m.height-8, m.width)
+	}
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • tab: extension/type MIME • enter: lister les fichiers • esc: retour")
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
+
+// Liste des fichiers du type choisi ; enter ouvre le dossier qui contient le fichier
+func (m Model) updateTypeFiles(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	files := m.typeStats()[m.typeSel].files
+	switch msg.String() {
+	case "esc", "q", "backspace", "left", "h":
+		m.panel = m.typePanel
+		m.state = StateTypes
+		return m, nil
+	case "enter":
+		if m.panel.cursor < len(files) {
+			m = m.revealNode(files[m.panel.cursor])
+		}
+		return m, nil
+	}
+	m.panel.handleKey(msg.String(), len(files), m.height-8)
+	return m, nil
+}
+
+func (m Model) viewTypeFiles() string {
+	s := m.typeStats()[m.typeSel]
+	title := titleStyle.Render("AED - " + s.key)
+	header := fmt.Sprintf("  %s  %s\n", title, infoStyle.Render(fmt.Sprintf("%d fichier(s), %s", len(s.files), formatBytes(s.size))))
+
+	rows := make([]string, len(s.files))
+	for i, f := range s.files {
+		rows[i] = fmt.Sprintf("%10s  %s", formatBytes(m.sizeOf(f)), f.Path)
+	}
+	content := m.panel.render(rows, m.height-8, m.width)
+
+	footer := helpStyle.Render("\n↑/↓: naviguer • enter: ouvrir le dossier • esc: retour")
+	return fmt.Sprintf("\n%s\n%s\n%s", header, content, footer)
+}
+
+// Ouvre l'explorateur sur le dossier parent d'un nœud, curseur placé sur celui-ci
+func (m Model) revealNode(n *FileNode) Model {
+	if n.Parent == nil {
+		return m
+	}
+	m.currentNode = n.Parent
+	m.cursor, m.yOffset = 0, 0
+	m.state = StateBrowsing
+	for i, item := range m.getDisplayItems() {
+		if item == n {
+			m.cursor = i
+			break
+		}
+	}
+	visibleHeight := m.listHeight()
+	if m.cursor >= visibleHeight {
+		m.yOffset = m.cursor - visibleHeight/2
+	}
+	return m
+}
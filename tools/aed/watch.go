@@ -0,0 +1,551 @@
+package aeddsa
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// Délai de regroupement des événements avant la mise à jour de l'arbre
+	watchBatchDelay = 300 * time.Millisecond
+	// Durée pendant laquelle un élément modifié reste en surbrillance
+	watchHighlight = 10 * time.Second
+
+	watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+		syscall.IN_DONT_FOLLOW | syscall.IN_EXCL_UNLINK
+)
+
+var changedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#39FF14")).Bold(true)
+
+// Événement inotify ramené au chemin concerné
+type watchEvent struct {
+	path string
+	mask uint32
+}
+
+// Surveillance inotify des dossiers de l'arbre
+// Le descripteur est non bloquant pour que sa fermeture interrompe la lecture en cours
+type watcher struct {
+	fd      int
+	file    *os.File
+	exclude *excluder
+	events  chan watchEvent
+	done    chan struct{}
+	ctx     context.Context // annulé à l'arrêt : interrompt les scans des dossiers apparus
+	cancel  context.CancelFunc
+
+	mu   sync.Mutex
+	dirs map[int32]string // descripteur de surveillance -> dossier
+}
+
+// Lot d'événements reçus, ou fin de la surveillance (closed)
+type watchEventsMsg struct {
+	w      *watcher
+	events []watchEvent
+	closed bool
+}
+
+// Message envoyé lorsque tous les dossiers de l'arbre sont surveillés
+type watchReadyMsg struct {
+	w     *watcher
+	count int
+	err   error
+}
+
+// Message envoyé lorsqu'un dossier apparu sous un dossier surveillé a été scanné
+type watchScannedMsg struct {
+	w          *watcher
+	path       string
+	root       *FileNode
+	exclusions []Exclusion
+	err        error
+}
+
+// Rafraîchissement périodique pour estomper la surbrillance
+type watchTickMsg struct {
+	w *watcher
+}
+
+func newWatcher(opts ScanOptions, rootDev uint64) (*watcher, error) {
+	exclude, err := newExcluder(opts, rootDev)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("inotify : %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{
+		ctx:     ctx,
+		cancel:  cancel,
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		exclude: exclude,
+		events:  make(chan watchEvent, 4096),
+		done:    make(chan struct{}),
+		dirs:    make(map[int32]string),
+	}
+	go w.read()
+	return w, nil
+}
+
+// Surveille un dossier ; ENOSPC signale la limite fs.inotify.max_user_watches
+func (w *watcher) add(path string) error {
+	wd, err := syscall.InotifyAddWatch(w.fd, path, watchMask)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.dirs[int32(wd)] = path
+	w.mu.Unlock()
+	return nil
+}
+
+// Oublie les surveillances d'un dossier et de ses sous-dossiers, déplacé hors de l'arbre
+func (w *watcher) forget(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for wd, dir := range w.dirs {
+		if isUnder(dir, path) {
+			syscall.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.dirs, wd)
+		}
+	}
+}
+
+// Reporte le renommage d'un dossier sur les chemins de ses surveillances, qui suivent l'inode
+func (w *watcher) rename(from, to string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for wd, dir := range w.dirs {
+		if isUnder(dir, from) {
+			w.dirs[wd] = to + strings.TrimPrefix(dir, from)
+		}
+	}
+}
+
+// Chemin égal à dir ou situé sous lui
+func isUnder(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// Surveille les dossiers donnés ; seule la limite ENOSPC interrompt l'ajout
+func (w *watcher) addAll(dirs []string) error {
+	for _, dir := range dirs {
+		if err := w.add(dir); errors.Is(err, syscall.ENOSPC) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Chemins des dossiers d'un sous-arbre, relevés dans Update avant de passer la main à une goroutine
+func dirPaths(n *FileNode) []string {
+	if !n.IsDir {
+		return nil
+	}
+	paths := []string{n.Path}
+	for _, child := range n.Children {
+		paths = append(paths, dirPaths(child)...)
+	}
+	return paths
+}
+
+func (w *watcher) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.dirs)
+}
+
+func (w *watcher) close() {
+	w.cancel()
+	close(w.done)
+	w.file.Close()
+}
+
+// Lit les événements bruts jusqu'à la fermeture du descripteur
+// Un dossier renommé dans l'arbre est suivi dès la lecture, avant que les événements suivants ne soient résolus
+func (w *watcher) read() {
+	defer close(w.events)
+	buf := make([]byte, 64*1024)
+	var movedCookie uint32
+	var movedFrom string
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			wd := int32(binary.NativeEndian.Uint32(buf[off:]))
+			mask := binary.NativeEndian.Uint32(buf[off+4:])
+			cookie := binary.NativeEndian.Uint32(buf[off+8:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+			start := off + syscall.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[start:start+nameLen]), "\x00")
+			off = start + nameLen
+
+			w.mu.Lock()
+			dir, ok := w.dirs[wd]
+			if mask&syscall.IN_IGNORED != 0 {
+				delete(w.dirs, wd)
+			}
+			w.mu.Unlock()
+
+			ev := watchEvent{mask: mask}
+			if mask&syscall.IN_Q_OVERFLOW == 0 {
+				if !ok || name == "" {
+					continue
+				}
+				ev.path = filepath.Join(dir, name)
+			}
+			if mask&syscall.IN_ISDIR != 0 {
+				switch {
+				case mask&syscall.IN_MOVED_FROM != 0:
+					movedCookie, movedFrom = cookie, ev.path
+				case mask&syscall.IN_MOVED_TO != 0 && cookie == movedCookie && movedFrom != "":
+					w.rename(movedFrom, ev.path)
+					movedFrom = ""
+				}
+			}
+			select {
+			case w.events <- ev:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// Attend le prochain événement puis regroupe ceux qui suivent pendant un court délai
+func waitWatchCmd(w *watcher) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-w.events
+		if !ok {
+			return watchEventsMsg{w: w, closed: true}
+		}
+		batch := []watchEvent{ev}
+		timeout := time.After(watchBatchDelay)
+		for {
+			select {
+			case ev, ok := <-w.events:
+				if !ok {
+					return watchEventsMsg{w: w, events: batch}
+				}
+				batch = append(batch, ev)
+			case <-timeout:
+				return watchEventsMsg{w: w, events: batch}
+			}
+		}
+	}
+}
+
+// Pose les surveillances sur les dossiers en arrière-plan
+func addWatchesCmd(w *watcher, dirs []string) tea.Cmd {
+	return func() tea.Msg {
+		err := w.addAll(dirs)
+		return watchReadyMsg{w: w, count: w.count(), err: err}
+	}
+}
+
+// Scanne en arrière-plan un dossier apparu, annulé avec la surveillance
+func scanWatchedCmd(w *watcher, path string, opts ScanOptions) tea.Cmd {
+	return func() tea.Msg {
+		root, exclusions, err := scanRecursively(w.ctx, path, opts, newScanProgress())
+		return watchScannedMsg{w: w, path: path, root: root, exclusions: exclusions, err: err}
+	}
+}
+
+func watchTick(w *watcher) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return watchTickMsg{w: w} })
+}
+
+// Active ou arrête la surveillance de l'arbre scanné
+func (m Model) toggleWatch() (Model, tea.Cmd) {
+	if m.watch != nil {
+		m.stopWatch()
+		m.status = "Surveillance arrêtée"
+		return m, nil
+	}
+	if m.fromSnapshot {
+		m.status = errorStyle.Render("Impossible de surveiller un instantané : relancez un scan du dossier")
+		return m, nil
+	}
+
+	w, err := newWatcher(m.options, m.root.Dev)
+	if err != nil {
+		m.status = errorStyle.Render(err.Error())
+		return m, nil
+	}
+	m.watch = w
+	m.changed = make(map[string]time.Time)
+	m.status = "Mise en place de la surveillance..."
+	return m, tea.Batch(addWatchesCmd(w, dirPaths(m.root)), waitWatchCmd(w), watchTick(w))
+}
+
+func (m *Model) stopWatch() {
+	if m.watch != nil {
+		m.watch.close()
+		m.watch = nil
+	}
+	m.changed = nil
+}
+
+// Élément modifié depuis peu (lui-même ou son contenu)
+func (m Model) recentlyChanged(n *FileNode) bool {
+	t, ok := m.changed[n.Path]
+	return ok && time.Since(t) < watchHighlight
+}
+
+// Note un changement sur un nœud et tous ses dossiers parents
+func (m Model) touch(n *FileNode, now time.Time) {
+	for p := n; p != nil; p = p.Parent {
+		m.changed[p.Path] = now
+	}
+}
+
+// Ajoute une variation de taille à tous les dossiers parents et propage les dates les plus récentes
+func resizeAncestors(n *FileNode, size, apparent int64) {
+	for p := n.Parent; p != nil; p = p.Parent {
+		p.Size = max(p.Size+size, 0)
+		p.Apparent = max(p.Apparent+apparent, 0)
+		p.absorbTimes(n)
+	}
+}
+
+// Répercute un lot d'événements sur l'arbre ; les dossiers apparus sont scannés en arrière-plan
+func (m Model) applyWatch(events []watchEvent) (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	now := time.Now()
+	removed := make(map[*FileNode]bool)
+	dirty := make(map[*FileNode]bool) // dossiers dont les enfants sont à retrier
+	refreshed := make(map[string]bool)
+
+	for _, ev := range events {
+		switch {
+		case ev.mask&syscall.IN_Q_OVERFLOW != 0:
+			m.status = errorStyle.Render("Trop d'événements : certaines modifications ont été perdues, relancez un scan")
+
+		case ev.mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+			delete(refreshed, ev.path)
+			n, scan := m.watchCreate(ev.path)
+			if n != nil {
+				dirty[n.Parent] = true
+				m.touch(n, now)
+			}
+			if scan {
+				cmds = append(cmds, scanWatchedCmd(m.watch, ev.path, m.options))
+			}
+
+		case ev.mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+			delete(refreshed, ev.path)
+			if n := findNode(m.root, ev.path); n != nil && n != m.root {
+				detachNode(n)
+				relinkRemaining(m.root, []*FileNode{n})
+				removed[n] = true
+				delete(m.marked, n.Path)
+				m.touch(n.Parent, now)
+			}
+			// Dossier déplacé hors de l'arbre : ses surveillances n'ont pas été renommées à la lecture
+			if ev.mask&(syscall.IN_MOVED_FROM|syscall.IN_ISDIR) == syscall.IN_MOVED_FROM|syscall.IN_ISDIR {
+				m.watch.forget(ev.path)
+			}
+
+		case ev.mask&syscall.IN_MODIFY != 0:
+			if refreshed[ev.path] {
+				continue
+			}
+			refreshed[ev.path] = true
+			n := findNode(m.root, ev.path)
+			if n == nil || n.IsDir {
+				continue
+			}
+			info, err := os.Lstat(ev.path)
+			if err != nil {
+				continue
+			}
+			updated := newFileNode(ev.path, info, n.Parent)
+			size, apparent := updated.Size-n.Size, updated.Apparent-n.Apparent
+			// Tous les liens physiques du fichier changent ; seule la taille du lien compté est dans celle des dossiers
+			for _, link := range linksOf(m.root, n) {
+				link.Size, link.Apparent, link.ModTime = updated.Size, updated.Apparent, updated.ModTime
+				if !link.Shared {
+					resizeAncestors(link, size, apparent)
+				}
+				if link != n {
+					dirty[link.Parent] = true
+					m.touch(link, now)
+				}
+			}
+			// Archive modifiée : son contenu sera relu à la prochaine ouverture
+			if n.Children != nil {
+				if archiveOf(m.currentNode) == n {
+					m.currentNode, m.cursor, m.yOffset = n.Parent, 0, 0
+				}
+				n.Children = nil
+			}
+			dirty[n.Parent] = true
+			m.touch(n, now)
+		}
+	}
+
+	m = m.forgetRemoved(removed)
+	m = m.refreshAgeFilter()
+	return m.keepSelection(func() {
+		for dir := range dirty {
+			sortChildren(dir, m.sortMode, m.sortReverse, m.sizeOf)
+		}
+	}), tea.Batch(cmds...)
+}
+
+// Ajoute à l'arbre un fichier apparu sous un dossier surveillé
+// Un dossier n'est pas ajouté tout de suite : le second résultat demande son scan en arrière-plan
+func (m *Model) watchCreate(path string) (*FileNode, bool) {
+	parent := findNode(m.root, filepath.Dir(path))
+	if parent == nil || !parent.IsDir {
+		return nil, false
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, false
+	}
+
+	dev := m.root.Dev
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		dev = stat.Dev
+	}
+	if excl, skip := m.watch.exclude.match(path, info.Name(), info.IsDir(), dev); skip {
+		m.exclusions = append(m.exclusions, excl)
+		m.mounts = append(m.mounts, skippedMounts([]Exclusion{excl})...)
+		return nil, false
+	}
+
+	// Un élément renommé par-dessus un autre le remplace
+	if old := findNode(m.root, path); old != nil {
+		detachNode(old)
+	}
+	if info.IsDir() {
+		return nil, true
+	}
+
+	n := newFileNode(path, info, parent)
+	// Nouveau lien d'un fichier déjà présent : sa taille est déjà comptée
+	if links := linksOf(m.root, n); len(links) > 1 {
+		for _, link := range links {
+			link.Nlink = n.Nlink
+		}
+		n.Shared = true
+	}
+	graftNode(parent, n)
+	return n, false
+}
+
+// Liens physiques de l'arbre pointant vers le même inode que n, n compris
+func linksOf(root, n *FileNode) []*FileNode {
+	links := []*FileNode{n}
+	if n.Ino == 0 || n.Nlink <= 1 {
+		return links
+	}
+	walkFiles([]*FileNode{root}, func(f *FileNode) {
+		if f != n && f.Ino == n.Ino && f.Dev == n.Dev {
+			links = append(links, f)
+		}
+	})
+	return links
+}
+
+// Rattache un nœud à son dossier et répercute sa taille et son nombre d'éléments sur les parents
+func graftNode(parent, n *FileNode) {
+	parent.Children = append(parent.Children, n)
+	size, apparent := countedSize(n)
+	resizeAncestors(n, size, apparent)
+	for p := parent; p != nil; p = p.Parent {
+		p.Count += 1 + n.Count
+		p.Unreadable += unreadable(n)
+	}
+}
+
+// Greffe le sous-arbre d'un dossier apparu une fois scanné, puis le surveille à son tour
+func (m Model) applyWatchScan(msg watchScannedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		return m, nil
+	}
+	parent := findNode(m.root, filepath.Dir(msg.path))
+	if parent == nil || !parent.IsDir {
+		return m, nil
+	}
+	// Le dossier a disparu ou été remplacé pendant le scan
+	if _, err := os.Lstat(msg.path); err != nil {
+		return m, nil
+	}
+	if old := findNode(m.root, msg.path); old != nil {
+		detachNode(old)
+	}
+
+	n := msg.root
+	n.Name, n.Parent = filepath.Base(msg.path), parent
+	sortTree(n, m.sortMode, m.sortReverse, m.sizeOf)
+	m.exclusions = append(m.exclusions, msg.exclusions...)
+	m.mounts = append(m.mounts, skippedMounts(msg.exclusions)...)
+	graftNode(parent, n)
+	m.touch(n, time.Now())
+
+	m = m.refreshAgeFilter()
+	m = m.keepSelection(func() { sortChildren(parent, m.sortMode, m.sortReverse, m.sizeOf) })
+	return m, addWatchesCmd(m.watch, dirPaths(n))
+}
+
+// Réception des messages de la surveillance ; ceux d'une surveillance arrêtée sont ignorés
+func (m Model) updateWatch(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case watchReadyMsg:
+		if msg.w != m.watch {
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Surveillance active : %d dossier(s)", msg.count)
+		if errors.Is(msg.err, syscall.ENOSPC) {
+			m.status += "\n  " + errorStyle.Render("Limite inotify atteinte (fs.inotify.max_user_watches) : une partie de l'arbre n'est pas surveillée")
+		}
+
+	case watchEventsMsg:
+		if msg.w != m.watch {
+			return m, nil
+		}
+		if msg.closed {
+			m.stopWatch()
+			m.status = errorStyle.Render("Surveillance interrompue")
+			return m, nil
+		}
+		m, cmd := m.applyWatch(msg.events)
+		return m, tea.Batch(cmd, waitWatchCmd(msg.w))
+
+	case watchScannedMsg:
+		if msg.w != m.watch {
+			return m, nil
+		}
+		return m.applyWatchScan(msg)
+
+	case watchTickMsg:
+		if msg.w != m.watch {
+			return m, nil
+		}
+		for path, t := range m.changed {
+			if time.Since(t) >= watchHighlight {
+				delete(m.changed, path)
+			}
+		}
+		return m, watchTick(msg.w)
+	}
+	return m, nil
+}
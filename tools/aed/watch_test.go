@@ -0,0 +1,46 @@
+package aeddsa
+
+import "testing"
+
+func TestWatcherMovedDirs(t *testing.T) {
+	newTestWatcher := func() *watcher {
+		return &watcher{fd: -1, dirs: map[int32]string{
+			1: "/r/a",
+			2: "/r/a/sous",
+			3: "/r/ab",
+			4: "/r/b",
+		}}
+	}
+
+	w := newTestWatcher()
+	w.rename("/r/a", "/r/b/a")
+	want := map[int32]string{1: "/r/b/a", 2: "/r/b/a/sous", 3: "/r/ab", 4: "/r/b"}
+	for wd, path := range want {
+		if w.dirs[wd] != path {
+			t.Errorf("renommage : surveillance %d sur %q, attendu %q", wd, w.dirs[wd], path)
+		}
+	}
+
+	w = newTestWatcher()
+	w.forget("/r/a")
+	if len(w.dirs) != 2 || w.dirs[3] != "/r/ab" || w.dirs[4] != "/r/b" {
+		t.Errorf("après oubli : %v", w.dirs)
+	}
+}
+
+// Nouveau lien physique d'un fichier déjà compté
+func TestLinksOf(t *testing.T) {
+	root := testRoot("/r")
+	a, b := testDir(root, "a"), testDir(root, "b")
+	first, second := testLinks(a, b, "lien", 1000)
+	testFile(a, "seul", 10)
+
+	links := linksOf(root, second)
+	if len(links) != 2 || links[0] != second || links[1] != first {
+		t.Errorf("%d lien(s) trouvé(s), attendu 2", len(links))
+	}
+	n := &FileNode{Name: "autre", Dev: 1, Ino: 7, Nlink: 2}
+	if got := len(linksOf(root, n)); got != 1 {
+		t.Errorf("inode absent : %d lien(s), attendu 1", got)
+	}
+}
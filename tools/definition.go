@@ -1,9 +1,58 @@
 package tools
 
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Signal commun de retour au menu principal, émis par tous les outils
+// Status est un message optionnel affiché sous le menu (ex: code de sortie d'un script)
+type BackMsg struct {
+	Status string
+}
+
+// Commande Tea renvoyant le signal de retour au menu
+func Back() tea.Msg {
+	return BackMsg{}
+}
+
+// Commande Tea de retour au menu avec un message de statut
+func BackWithStatus(status string) tea.Cmd {
+	return func() tea.Msg { return BackMsg{Status: status} }
+}
+
+// Dossier de configuration de cyberTools ($XDG_CONFIG_HOME/cyberTools ou ~/.config/cyberTools)
+func ConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cyberTools")
+}
+
+// Constructeur d'un outil : reçoit les dimensions courantes du terminal
+type Constructor func(w, h int) tea.Model
+
+// Ouverture directe d'un outil sur un fichier ou dossier passé en ligne de commande
+type Opener func(w, h int, arg string) (tea.Model, error)
+
 type Tool struct {
 	Name        string
 	Description string
-	Action      func()
+	Category    string
+	New         Constructor
+
+	// Sous-commande de la ligne de commande (ex: "logv") et ouverture associée, optionnelles
+	Command string
+	Usage   string
+	Open    Opener
+
+	// Mode non interactif optionnel (cyberTools <commande> --report ...), renvoie le code de sortie
+	Headless      func(args []string) int
+	HeadlessUsage string
 }
 
 type Category struct {
@@ -11,26 +60,70 @@ type Category struct {
 	Tools []Tool
 }
 
+// Ordre d'affichage des catégories connues, les autres sont ajoutées à la suite
+var categoryOrder = []string{"BDD", "Utilitaire", "Data"}
+
+// Registre global des outils, alimenté par Register depuis les paquets des outils
+var registry []Tool
+
+// Enregistre un outil dans le registre (appelé depuis la fonction init de chaque outil)
+func Register(t Tool) {
+	registry = append(registry, t)
+}
+
+// Regroupe les outils enregistrés par catégorie en respectant l'ordre d'enregistrement
+// Cet ordre est stable : depuis Go 1.21, les fonctions init des paquets s'exécutent dans l'ordre
+// alphabétique de leur chemin d'import (et non dans l'ordre des imports) ; scripts et plugins viennent ensuite
 func GetCategories() []Category {
-	return []Category{
-		{
-			Name: "BDD",
-			Tools: []Tool{
-				{Name: "SqlTUI", Description: "Explorateur SQL"},
-			},
-		},
-		{
-			Name: "Utilitaire",
-			Tools: []Tool{
-				{Name: "LogV", Description: "Visualiseur de logs"},
-				{Name: "structViewer", Description: "Lecteur YAML/JSON arborescent"},
-			},
-		},
-		{
-			Name: "Data",
-			Tools: []Tool{
-				{Name: "AED", Description: "Analyseur d'espace disque"},
-			},
-		},
+	order := append([]string{}, categoryOrder...)
+	byName := make(map[string][]Tool)
+
+	for _, t := range registry {
+		if _, ok := byName[t.Category]; !ok && !contains(order, t.Category) {
+			order = append(order, t.Category)
+		}
+		byName[t.Category] = append(byName[t.Category], t)
+	}
+
+	var categories []Category
+	for _, name := range order {
+		if len(byName[name]) == 0 {
+			continue
+		}
+		categories = append(categories, Category{Name: name, Tools: byName[name]})
+	}
+	return categories
+}
+
+// Recherche un outil par sa sous-commande
+func Find(command string) (Tool, bool) {
+	for _, t := range registry {
+		if t.Command != "" && t.Command == command {
+			return t, true
+		}
+	}
+	return Tool{}, false
+}
+
+// Liste des outils accessibles en ligne de commande
+func Commands() []Tool {
+	var cmds []Tool
+	for _, t := range registry {
+		if t.Command != "" && t.Open != nil {
+			cmds = append(cmds, t)
+		}
+	}
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].Command < cmds[j].Command
+	})
+	return cmds
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,220 @@
+package logv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Intervalle de vérification du fichier suivi
+const followInterval = 500 * time.Millisecond
+
+// Identité du fichier lu : un changement d'inode signale une rotation
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// Position de lecture du fichier suivi
+// Le descripteur reste ouvert d'un tour à l'autre pour pouvoir finir de lire l'ancien fichier après une rotation
+type followState struct {
+	gen    int // incrémenté à chaque arrêt pour ignorer les vérifications en vol
+	file   *os.File
+	id     fileID
+	offset int64 // octets déjà lus
+}
+
+// Résultat d'une vérification : données ajoutées depuis la dernière lecture
+type followMsg struct {
+	gen    int
+	file   *os.File // descripteur à utiliser au prochain tour
+	id     fileID
+	offset int64
+	data   string // fin du fichier déjà suivi
+	reset  string // cause de la relecture depuis le début (rotation, troncature), vide sinon
+	fresh  string // contenu relu depuis le début après une rotation ou une troncature
+	err    error
+}
+
+// Identité d'un fichier à partir de son stat
+func idOf(info os.FileInfo) fileID {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fileID{dev: uint64(st.Dev), ino: st.Ino}
+	}
+	return fileID{}
+}
+
+// Lit le fichier à partir de la position donnée jusqu'à sa fin actuelle
+func readFrom(f *os.File, offset int64) (string, error) {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(f)
+	return string(data), err
+}
+
+// Commande Tea : attend l'intervalle puis lit ce qui a été ajouté au fichier
+func followCmd(path string, st followState) tea.Cmd {
+	return tea.Tick(followInterval, func(time.Time) tea.Msg {
+		return checkFollow(path, st)
+	})
+}
+
+// Lit les ajouts au fichier suivi, comme tail -F :
+//   - une taille inférieure à la position lue (troncature) fait tout relire ;
+//   - un autre inode au même chemin (rotation) fait d'abord finir l'ancien fichier, puis lire le nouveau depuis le début.
+//
+// L'identité et la taille viennent toujours du descripteur ouvert (fstat), jamais d'un stat séparé du chemin
+func checkFollow(path string, st followState) followMsg {
+	msg := followMsg{gen: st.gen, file: st.file, id: st.id, offset: st.offset}
+
+	// Premier tour : le fichier est rouvert et comparé à celui chargé à l'ouverture
+	if msg.file == nil {
+		f, err := os.Open(path)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			msg.err = err
+			return msg
+		}
+		msg.file = f
+		if id := idOf(info); id != st.id {
+			return reopen(msg, f, id, "fichier remplacé (rotation)")
+		}
+	}
+
+	info, err := msg.file.Stat()
+	if err != nil {
+		msg.err = err
+		return msg
+	}
+	if info.Size() < msg.offset {
+		return reopen(msg, msg.file, msg.id, "fichier tronqué")
+	}
+	if info.Size() > msg.offset {
+		data, err := readFrom(msg.file, msg.offset)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.data = data
+		msg.offset += int64(len(data))
+	}
+
+	// Le chemin désigne-t-il encore le fichier lu ? Absent pendant la rotation : nouvelle tentative au prochain tour
+	f, err := os.Open(path)
+	if err != nil {
+		return msg
+	}
+	next, err := f.Stat()
+	if err != nil || idOf(next) == msg.id {
+		f.Close()
+		return msg
+	}
+	msg.file.Close()
+	return reopen(msg, f, idOf(next), "fichier remplacé (rotation)")
+}
+
+// Relit un fichier depuis le début après une rotation ou une troncature
+func reopen(msg followMsg, f *os.File, id fileID, reason string) followMsg {
+	msg.file, msg.id, msg.reset = f, id, reason
+	fresh, err := readFrom(f, 0)
+	if err != nil {
+		msg.err = err
+		msg.offset = 0
+		return msg
+	}
+	msg.fresh = fresh
+	msg.offset = int64(len(fresh))
+	return msg
+}
+
+// Active ou désactive le suivi du fichier affiché
+func (m Model) toggleFollow() (Model, tea.Cmd) {
+	if m.following {
+		m.stopFollow()
+		return m, nil
+	}
+	m.following = true
+	m.newLines = 0
+	m.followErr = nil
+	m.viewport.GotoBottom()
+	return m, followCmd(m.selectedFile, m.follow)
+}
+
+// Arrête le suivi ; la vérification en cours sera ignorée
+func (m *Model) stopFollow() {
+	m.following = false
+	m.follow.gen++
+	if m.follow.file != nil {
+		m.follow.file.Close()
+		m.follow.file = nil
+	}
+	m.newLines = 0
+}
+
+// Ajoute les lignes lues au contenu ; la vue reste en bas sauf si l'utilisateur est remonté
+func (m Model) applyFollow(msg followMsg) (Model, tea.Cmd) {
+	if !m.following || msg.gen != m.follow.gen {
+		// Vérification d'un suivi arrêté : son descripteur n'est plus utilisé
+		if msg.file != nil && msg.file != m.follow.file {
+			msg.file.Close()
+		}
+		return m, nil
+	}
+	m.follow.file, m.follow.id, m.follow.offset = msg.file, msg.id, msg.offset
+	m.followErr = msg.err
+	if msg.data == "" && msg.reset == "" {
+		return m, followCmd(m.selectedFile, m.follow)
+	}
+
+	atBottom := m.viewport.AtBottom()
+	added := m.appendData(msg.data)
+	if msg.reset != "" {
+		// La dernière ligne, vide, n'appartient plus au fichier lu
+		if last := len(m.content) - 1; last >= 0 && m.content[last] == "" {
+			m.content = m.content[:last]
+		}
+		m.content = append(m.content, fmt.Sprintf("── %s à %s, relecture depuis le début ──", msg.reset, time.Now().Format("15:04:05")), "")
+		added++
+		added += m.appendData(msg.fresh)
+	}
+
+	m.renderAppended()
+	if atBottom {
+		m.viewport.GotoBottom()
+	} else {
+		m.newLines += added
+	}
+	return m, followCmd(m.selectedFile, m.follow)
+}
+
+// Ajoute des données lues au contenu et renvoie le nombre de lignes terminées visibles avec le filtre courant
+// La dernière entrée du contenu est la ligne en cours d'écriture (vide si le fichier finit par un saut de ligne)
+func (m *Model) appendData(data string) int {
+	if data == "" {
+		return 0
+	}
+	parts := strings.Split(data, "\n")
+	last := len(m.content) - 1
+	m.content[last] += parts[0]
+	m.content = append(m.content, parts[1:]...)
+
+	added := 0
+	filter := strings.ToLower(m.textInput.Value())
+	for _, line := range m.content[last : len(m.content)-1] {
+		if matchesFilter(line, filter) {
+			added++
+		}
+	}
+	return added
+}
@@ -0,0 +1,154 @@
+package logv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Modèle en cours de suivi d'un contenu donné, vue remontée en haut
+func followingModel(content, filter string) Model {
+	m := New(80, 6)
+	m.content = strings.Split(content, "\n")
+	m.textInput.SetValue(filter)
+	m.following = true
+	m.renderContent()
+	m.viewport.GotoTop()
+	return m
+}
+
+func TestApplyFollowLineCount(t *testing.T) {
+	long := strings.Repeat("ligne\n", 20)
+	tests := []struct {
+		name      string
+		content   string
+		filter    string
+		msg       followMsg
+		wantLines int
+		wantLast  string
+		wantNew   int
+	}{
+		{"lignes complètes", long, "", followMsg{data: "a\nb\n"}, 23, "", 2},
+		{"ligne partielle non comptée", long, "", followMsg{data: "a\nb"}, 22, "b", 1},
+		{"fin d'une ligne partielle", long + "déb", "", followMsg{data: "ut\n"}, 22, "", 1},
+		{"filtre", strings.Repeat("error\n", 20), "error", followMsg{data: "ERROR x\ninfo\nerror y\n"}, 24, "", 2},
+		{"rien de nouveau", long, "", followMsg{}, 21, "", 0},
+		{"relecture", long, "", followMsg{data: "fin\n", reset: "fichier tronqué", fresh: "x\n"}, 24, "", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := followingModel(tt.content, tt.filter)
+			m, cmd := m.applyFollow(tt.msg)
+			if cmd == nil {
+				t.Fatal("le suivi doit programmer la vérification suivante")
+			}
+			if len(m.content) != tt.wantLines {
+				t.Errorf("%d lignes, attendu %d", len(m.content), tt.wantLines)
+			}
+			if last := m.content[len(m.content)-1]; last != tt.wantLast {
+				t.Errorf("dernière ligne %q, attendu %q", last, tt.wantLast)
+			}
+			if m.newLines != tt.wantNew {
+				t.Errorf("newLines = %d, attendu %d", m.newLines, tt.wantNew)
+			}
+		})
+	}
+}
+
+func TestApplyFollowStale(t *testing.T) {
+	m := followingModel("a\n", "")
+	m.stopFollow()
+	m, cmd := m.applyFollow(followMsg{gen: m.follow.gen - 1, data: "b\n"})
+	if cmd != nil || len(m.content) != 2 {
+		t.Fatalf("une vérification d'un suivi arrêté doit être ignorée (%d lignes)", len(m.content))
+	}
+}
+
+// Le rendu incrémental doit donner le même résultat qu'un rendu complet
+func TestRenderAppended(t *testing.T) {
+	m := followingModel("INFO a\nWARN b\n", "")
+	m, _ = m.applyFollow(followMsg{data: "ERROR c\nd"})
+	got := m.viewport.View()
+	m.renderContent()
+	if want := m.viewport.View(); got != want {
+		t.Fatalf("rendu incrémental :\n%s\nrendu complet :\n%s", got, want)
+	}
+}
+
+func TestCheckFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write := func(data string, flag int) {
+		f, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(data)
+		f.Close()
+	}
+	write("a\n", os.O_TRUNC)
+
+	m := New(80, 6)
+	m, _ = m.loadFile(path)
+	m, _ = m.toggleFollow()
+	defer m.stopFollow()
+
+	steps := []struct {
+		name      string
+		change    func()
+		wantData  string
+		wantReset bool
+		wantFresh string
+	}{
+		{"ajout", func() { write("b\n", os.O_APPEND) }, "b\n", false, ""},
+		{"sans changement", func() {}, "", false, ""},
+		{"troncature", func() { write("c\n", os.O_TRUNC) }, "", true, "c\n"},
+		{"rotation", func() {
+			// Dernière ligne écrite dans l'ancien fichier après son renommage
+			if err := os.Rename(path, path+".1"); err != nil {
+				t.Fatal(err)
+			}
+			f, _ := os.OpenFile(path+".1", os.O_APPEND|os.O_WRONLY, 0)
+			f.WriteString("d\n")
+			f.Close()
+			write("e\n", os.O_TRUNC)
+		}, "d\n", true, "e\n"},
+	}
+	for _, st := range steps {
+		st.change()
+		msg := checkFollow(path, m.follow)
+		if msg.err != nil {
+			t.Fatalf("%s : %v", st.name, msg.err)
+		}
+		if msg.data != st.wantData || (msg.reset != "") != st.wantReset || msg.fresh != st.wantFresh {
+			t.Errorf("%s : data %q reset %q fresh %q", st.name, msg.data, msg.reset, msg.fresh)
+		}
+		m, _ = m.applyFollow(msg)
+	}
+	if last := m.content[len(m.content)-2]; last != "e" {
+		t.Errorf("dernière ligne lue %q, attendu %q", last, "e")
+	}
+}
+
+// Quitter avec ctrl+c arrête le suivi et ferme le fichier suivi
+func TestCtrlCStopsFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := followingModel("a\n", "")
+	m.follow.file = f
+	gen := m.follow.gen
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = next.(Model)
+	if m.following || m.follow.file != nil || m.follow.gen == gen {
+		t.Fatal("le suivi doit être arrêté en quittant")
+	}
+	if err := f.Close(); err == nil {
+		t.Error("le fichier suivi doit être fermé")
+	}
+}
@@ -2,9 +2,12 @@ package logv
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/Quirky1869/cyberTools/tools"
 	"github.com/charmbracelet/bubbles/filepicker"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -13,8 +16,18 @@ import (
 	"github.com/ncruces/zenity"
 )
 
-// Message de retour pour signaler au menu principal de reprendre la main
-type BackMsg struct{}
+// Enregistrement de l'outil dans le menu principal
+func init() {
+	tools.Register(tools.Tool{
+		Name:        "LogV",
+		Description: "Visualiseur de logs",
+		Category:    "Utilitaire",
+		New:         func(w, h int) tea.Model { return New(w, h) },
+		Command:     "logv",
+		Usage:       "<fichier>",
+		Open:        func(w, h int, arg string) (tea.Model, error) { return Open(w, h, arg) },
+	})
+}
 
 // États de la machine à états interne
 type SessionState int
@@ -30,6 +43,7 @@ var (
 	titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF2A6D")).Bold(true)
 	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Bold(true)
 	warnStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500")).Bold(true)
+	newStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#39FF14")).Bold(true)
 	infoStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#00f6ff"))
 
 	helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff00d4"))
@@ -49,6 +63,17 @@ type Model struct {
 	height       int
 	filtering    bool
 	enteringPath bool
+
+	// Suivi du fichier (tail -f) : lignes arrivées pendant que la vue n'est pas en bas
+	following bool
+	follow    followState
+	newLines  int
+	followErr error
+
+	// Rendu des lignes terminées, conservé pour n'ajouter que les nouvelles lignes pendant le suivi
+	rendered       string
+	renderedCount  int
+	renderedFilter string
 }
 
 // Initialisation des composants avec configuration des couleurs et dimensions
@@ -91,6 +116,17 @@ func New(w, h int) Model {
 	}
 }
 
+// Ouvre directement le fichier donné en mode visualisation (sans passer par le sélecteur)
+func Open(w, h int, path string) (Model, error) {
+	m := New(w, h)
+	if _, err := os.Stat(path); err != nil {
+		return m, err
+	}
+	m.filePicker.CurrentDirectory = filepath.Dir(path)
+	m, _ = m.loadFile(path)
+	return m, nil
+}
+
 func (m Model) Init() tea.Cmd {
 	return m.filePicker.Init()
 }
@@ -107,10 +143,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.Height = msg.Height - 4
 		m.filePicker.Height = msg.Height - 8
 
+	case followMsg:
+		return m.applyFollow(msg)
+
 	case tea.KeyMsg:
 		// Sortie globale si aucune saisie n'est en cours
 		if !m.filtering && !m.enteringPath && (msg.String() == "ctrl+c") {
-			return m, func() tea.Msg { return BackMsg{} }
+			m.stopFollow()
+			return m, tools.Back
 		}
 	}
 
@@ -121,7 +161,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg, ok := msg.(tea.KeyMsg); ok {
 			switch msg.String() {
 			case "q":
-				return m, func() tea.Msg { return BackMsg{} }
+				m.stopFollow()
+				return m, tools.Back
 			case "t":
 				m.state = StatePickingFile
 				m.filePicker.CurrentDirectory, _ = os.Getwd()
@@ -209,12 +250,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "backspace":
 				m.textInput.Reset()
 				m.applyFilter()
+			// f reste le défilement par page du viewport
+			case "F":
+				return m.toggleFollow()
+			case "G", "end":
+				m.viewport.GotoBottom()
+				m.newLines = 0
+				return m, nil
 			case "esc", "q":
+				m.stopFollow()
 				m.state = StatePickingFile
 				return m, nil
 			}
 			m.viewport, cmd = m.viewport.Update(msg)
 			cmds = append(cmds, cmd)
+			if m.viewport.AtBottom() {
+				m.newLines = 0
+			}
 		}
 	}
 
@@ -258,7 +310,16 @@ func (m Model) View() string {
 
 	case StateViewing:
 		header := titleStyle.Render("LogV: " + m.selectedFile)
-		footer := infoStyle.Render("\n[ / ] Filtrer  [ Bksp ] Reset Filtre  [ q ] Retour")
+		if m.following {
+			header += "  " + newStyle.Render("● suivi")
+			if m.newLines > 0 {
+				header += "  " + newStyle.Render(fmt.Sprintf("▼ %d nouvelle(s) ligne(s)", m.newLines))
+			}
+			if m.followErr != nil {
+				header += "  " + errorStyle.Render(m.followErr.Error())
+			}
+		}
+		footer := infoStyle.Render("\n[ / ] Filtrer  [ Bksp ] Reset Filtre  [ F ] Suivre  [ G ] Fin  [ q ] Retour")
 
 		if m.filtering {
 			footer = fmt.Sprintf("\nFiltre : %s", m.textInput.View())
@@ -271,43 +332,92 @@ func (m Model) View() string {
 
 // Charge le fichier en mémoire et applique le filtre initial
 func (m Model) loadFile(path string) (Model, tea.Cmd) {
+	m.stopFollow()
 	m.selectedFile = path
-	content, err := os.ReadFile(path)
+	f, err := os.Open(path)
+	if err != nil {
+		return m, nil
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
 	if err != nil {
 		return m, nil
 	}
 	m.content = strings.Split(string(content), "\n")
 
+	// Position de départ d'un éventuel suivi, prise sur le descripteur lu
+	m.follow.offset = int64(len(content))
+	m.follow.id = fileID{}
+	if info, err := f.Stat(); err == nil {
+		m.follow.id = idOf(info)
+	}
+
 	m.applyFilter()
 	m.state = StateViewing
 	return m, nil
 }
 
-// Applique le filtre de texte et la coloration syntaxique des logs
+// Applique le filtre de texte et revient en haut du contenu (en bas pendant le suivi)
 func (m *Model) applyFilter() {
-	var builder strings.Builder
-	filter := strings.ToLower(m.textInput.Value())
+	m.renderContent()
+	if m.following {
+		m.viewport.GotoBottom()
+		m.newLines = 0
+	} else {
+		m.viewport.GotoTop()
+	}
+}
 
-	for _, line := range m.content {
-		lineLower := strings.ToLower(line)
-		lineUpper := strings.ToUpper(line)
+// Filtre et colore les lignes selon les mots-clés, sans changer la position de lecture
+func (m *Model) renderContent() {
+	m.rendered, m.renderedCount = "", 0
+	m.renderedFilter = strings.ToLower(m.textInput.Value())
+	m.renderAppended()
+}
 
-		if filter != "" && !strings.Contains(lineLower, filter) {
-			continue
-		}
+// Ajoute au rendu les lignes terminées depuis le dernier appel ; tout est recalculé si le filtre a changé
+// La dernière ligne, encore en cours d'écriture, est rendue à chaque fois sans être conservée
+func (m *Model) renderAppended() {
+	filter := strings.ToLower(m.textInput.Value())
+	if filter != m.renderedFilter {
+		m.renderContent()
+		return
+	}
+	if len(m.content) == 0 {
+		m.viewport.SetContent("")
+		return
+	}
 
-		// Coloration basée sur les mots-clés standards (ERROR, WARN, INFO)
-		coloredLine := line
-		if strings.Contains(lineUpper, "ERROR") || strings.Contains(lineUpper, "FAIL") || strings.Contains(lineUpper, "CRIT") || strings.Contains(lineUpper, "FATAL") {
-			coloredLine = errorStyle.Render(line)
-		} else if strings.Contains(lineUpper, "WARN") {
-			coloredLine = warnStyle.Render(line)
-		} else if strings.Contains(lineUpper, "INFO") || strings.Contains(lineUpper, "DEBUG") || strings.Contains(lineUpper, "NOTICE") {
-			coloredLine = infoStyle.Render(line)
+	var builder strings.Builder
+	builder.WriteString(m.rendered)
+	done := len(m.content) - 1
+	for _, line := range m.content[m.renderedCount:done] {
+		if matchesFilter(line, filter) {
+			builder.WriteString(colorLine(line) + "\n")
 		}
+	}
+	m.rendered, m.renderedCount = builder.String(), done
 
-		builder.WriteString(coloredLine + "\n")
+	if last := m.content[done]; matchesFilter(last, filter) {
+		builder.WriteString(colorLine(last) + "\n")
 	}
 	m.viewport.SetContent(builder.String())
-	m.viewport.GotoTop()
+}
+
+// Coloration basée sur les mots-clés standards (ERROR, WARN, INFO)
+func colorLine(line string) string {
+	lineUpper := strings.ToUpper(line)
+	if strings.Contains(lineUpper, "ERROR") || strings.Contains(lineUpper, "FAIL") || strings.Contains(lineUpper, "CRIT") || strings.Contains(lineUpper, "FATAL") {
+		return errorStyle.Render(line)
+	} else if strings.Contains(lineUpper, "WARN") {
+		return warnStyle.Render(line)
+	} else if strings.Contains(lineUpper, "INFO") || strings.Contains(lineUpper, "DEBUG") || strings.Contains(lineUpper, "NOTICE") {
+		return infoStyle.Render(line)
+	}
+	return line
+}
+
+// Indique si la ligne contient le filtre (déjà en minuscules), toujours vrai sans filtre
+func matchesFilter(line, filter string) bool {
+	return filter == "" || strings.Contains(strings.ToLower(line), filter)
 }
\ No newline at end of file
@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/Quirky1869/cyberTools/tools"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Message de lancement du plugin dès l'ouverture de l'outil
+type startMsg struct{}
+
+// Message envoyé lorsque le plugin rend la main
+type pluginExitedMsg struct {
+	err error
+}
+
+// Définition des styles de l'interface
+var (
+	titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF2A6D")).Bold(true)
+	infoStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#00f6ff"))
+)
+
+// Modèle d'un plugin : lance l'exécutable en plein écran puis revient au menu à sa sortie
+type Model struct {
+	plugin        Plugin
+	width, height int
+}
+
+// Initialisation du modèle à partir du plugin découvert
+func New(p Plugin, w, h int) Model {
+	return Model{plugin: p, width: w, height: h}
+}
+
+func (m Model) Init() tea.Cmd {
+	return func() tea.Msg { return startMsg{} }
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	// Le plugin prend le contrôle du terminal ; il est informé qu'il tourne sous cyberTools
+	case startMsg:
+		c := exec.Command(m.plugin.Path)
+		c.Env = append(os.Environ(),
+			"CYBERTOOLS=1",
+			fmt.Sprintf("CYBERTOOLS_PROTOCOL=%d", ProtocolVersion),
+		)
+		return m, tea.ExecProcess(c, func(err error) tea.Msg {
+			return pluginExitedMsg{err: err}
+		})
+
+	case pluginExitedMsg:
+		return m, tools.BackWithStatus(m.statusLine(msg.err))
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	return fmt.Sprintf("\n  %s\n\n  %s", titleStyle.Render(m.plugin.Manifest.Name), infoStyle.Render("Lancement du plugin..."))
+}
+
+// Message de statut affiché au retour dans le menu
+func (m Model) statusLine(err error) string {
+	name := m.plugin.Manifest.Name
+	if err == nil {
+		return fmt.Sprintf("%s : terminé", name)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Sprintf("%s : code de sortie %d", name, exitErr.ExitCode())
+	}
+	return fmt.Sprintf("%s : échec du lancement (%v)", name, err)
+}
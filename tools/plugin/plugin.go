@@ -0,0 +1,183 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Quirky1869/cyberTools/tools"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Version du protocole de handshake supportée
+const ProtocolVersion = 1
+
+// Argument passé au plugin pour obtenir sa description
+const HandshakeArg = "--cybertools-handshake"
+
+// Délai maximal accordé à un plugin pour répondre au handshake
+const handshakeTimeout = 2 * time.Second
+
+// Nombre maximal de handshakes menés en parallèle
+const handshakeWorkers = 8
+
+// Réponse JSON attendue sur la sortie standard du plugin lors du handshake
+type Manifest struct {
+	Protocol    int    `json:"protocol"`
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// Plugin découvert : exécutable et description obtenue par handshake
+type Plugin struct {
+	Path     string
+	Manifest Manifest
+}
+
+// Dossier de découverte des plugins ($XDG_CONFIG_HOME/cyberTools/plugins)
+func DefaultDir() string {
+	dir := tools.ConfigDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "plugins")
+}
+
+// Parcourt le dossier et interroge chaque exécutable ; les plugins invalides sont renvoyés en erreur sans bloquer les autres
+func Discover(dir string) ([]Plugin, []error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	// Handshakes en parallèle : un plugin lent ne retarde pas les autres au-delà de son délai
+	type result struct {
+		manifest Manifest
+		err      error
+	}
+	results := make([]result, len(paths))
+	sem := make(chan struct{}, handshakeWorkers)
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].manifest, results[i].err = handshake(path)
+		}()
+	}
+	wg.Wait()
+
+	var plugins []Plugin
+	var errs []error
+	for i, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", filepath.Base(paths[i]), r.err))
+			continue
+		}
+		plugins = append(plugins, Plugin{Path: paths[i], Manifest: r.manifest})
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Manifest.Name < plugins[j].Manifest.Name
+	})
+
+	return plugins, errs
+}
+
+// Exécute le plugin avec l'argument de handshake et décode la première ligne JSON de sa sortie
+func handshake(path string) (Manifest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+
+	// Groupe de processus propre : le délai dépassé tue aussi les processus lancés par le plugin,
+	// et WaitDelay évite de rester bloqué sur une sortie qu'ils garderaient ouverte
+	cmd := exec.CommandContext(ctx, path, HandshakeArg)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = handshakeTimeout
+
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return Manifest{}, fmt.Errorf("pas de réponse au handshake après %s", handshakeTimeout)
+	}
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	if !scanner.Scan() {
+		return Manifest{}, errors.New("réponse de handshake vide")
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(scanner.Bytes(), &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("réponse de handshake invalide: %w", err)
+	}
+
+	if manifest.Protocol != ProtocolVersion {
+		return Manifest{}, fmt.Errorf("protocole %d non supporté (attendu %d)", manifest.Protocol, ProtocolVersion)
+	}
+	if manifest.Name == "" {
+		return Manifest{}, errors.New("nom manquant dans la réponse de handshake")
+	}
+	if manifest.Category == "" {
+		manifest.Category = "Plugins"
+	}
+
+	return manifest, nil
+}
+
+// Découvre les plugins du dossier par défaut et les enregistre dans le registre des outils
+func RegisterFromDir() []error {
+	dir := DefaultDir()
+	if dir == "" {
+		return nil
+	}
+
+	plugins, errs := Discover(dir)
+	for _, p := range plugins {
+		plug := p
+		desc := plug.Manifest.Description
+		if plug.Manifest.Version != "" {
+			desc = fmt.Sprintf("%s v%s", desc, plug.Manifest.Version)
+		}
+		tools.Register(tools.Tool{
+			Name:        plug.Manifest.Name,
+			Description: desc,
+			Category:    plug.Manifest.Category,
+			New:         func(w, h int) tea.Model { return New(plug, w, h) },
+		})
+	}
+	return errs
+}
@@ -0,0 +1,115 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Quirky1869/cyberTools/tools"
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// Modes d'exécution d'un script
+const (
+	ModeExec    = "exec"    // Le script prend le contrôle du terminal (tea.ExecProcess)
+	ModeCapture = "capture" // La sortie est capturée et affichée dans un panneau défilant
+)
+
+// Fichier de configuration déclarant les catégories et scripts additionnels
+type Config struct {
+	Categories []CategoryConfig `yaml:"categories"`
+}
+
+type CategoryConfig struct {
+	Name  string       `yaml:"name"`
+	Tools []ToolConfig `yaml:"tools"`
+}
+
+// Déclaration d'un outil externe basé sur une commande shell
+// Les occurrences de {{nom}} dans Command sont remplacées par la valeur saisie de l'argument correspondant
+type ToolConfig struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description"`
+	Command     string      `yaml:"command"`
+	Mode        string      `yaml:"mode"`
+	Dir         string      `yaml:"dir"`
+	Args        []ArgConfig `yaml:"args"`
+}
+
+// Argument demandé à l'utilisateur dans le formulaire avant l'exécution
+type ArgConfig struct {
+	Name    string `yaml:"name"`
+	Prompt  string `yaml:"prompt"`
+	Default string `yaml:"default"`
+}
+
+// Chemin par défaut du fichier de configuration
+func DefaultConfigPath() string {
+	dir := tools.ConfigDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+// Lit et valide le fichier de configuration
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, cat := range cfg.Categories {
+		if cat.Name == "" {
+			return nil, fmt.Errorf("%s: catégorie sans nom", path)
+		}
+		for _, t := range cat.Tools {
+			if t.Name == "" || t.Command == "" {
+				return nil, fmt.Errorf("%s: l'outil %q de la catégorie %q doit avoir un nom et une commande", path, t.Name, cat.Name)
+			}
+			if t.Mode != "" && t.Mode != ModeExec && t.Mode != ModeCapture {
+				return nil, fmt.Errorf("%s: mode %q inconnu pour l'outil %q (exec ou capture)", path, t.Mode, t.Name)
+			}
+		}
+	}
+
+	return &cfg, nil
+}
+
+// Charge la configuration par défaut et enregistre les scripts dans le registre des outils
+// L'absence du fichier n'est pas une erreur
+func RegisterFromConfig() error {
+	path := DefaultConfigPath()
+	if path == "" {
+		return nil
+	}
+
+	cfg, err := LoadConfig(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, cat := range cfg.Categories {
+		for _, t := range cat.Tools {
+			tc := t
+			tools.Register(tools.Tool{
+				Name:        tc.Name,
+				Description: tc.Description,
+				Category:    cat.Name,
+				New:         func(w, h int) tea.Model { return New(tc, w, h) },
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Quirky1869/cyberTools/tools"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// États de la machine à états interne
+type SessionState int
+
+const (
+	StateForm SessionState = iota
+	StateRunning
+	StateOutput
+)
+
+// Message de lancement immédiat pour les scripts sans argument
+type startMsg struct{}
+
+// Délai laissé aux descendants du script pour libérer sa sortie une fois celui-ci terminé ou annulé
+const runWaitDelay = 2 * time.Second
+
+// Message envoyé à la fin de l'exécution du script
+type scriptDoneMsg struct {
+	run    int // exécution d'origine : le résultat d'une exécution annulée est ignoré
+	output string
+	err    error
+}
+
+// Définition des styles de l'interface
+var (
+	titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF2A6D")).Bold(true)
+	labelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00f6ff"))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Bold(true)
+	okStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#39FF14")).Bold(true)
+	helpStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff00d4"))
+	cmdStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+)
+
+// Modèle d'un outil externe : formulaire d'arguments puis exécution de la commande
+type Model struct {
+	state    SessionState
+	tool     ToolConfig
+	inputs   []textinput.Model
+	focus    int
+	viewport viewport.Model
+	command  string
+	exitCode int
+	err      error
+	runID    int                // incrémenté à chaque lancement et à chaque annulation
+	cancel   context.CancelFunc // annule l'exécution en cours
+
+	width, height int
+}
+
+// Initialisation du formulaire à partir de la déclaration de l'outil
+func New(t ToolConfig, w, h int) Model {
+	inputs := make([]textinput.Model, len(t.Args))
+	for i, arg := range t.Args {
+		ti := textinput.New()
+		ti.Placeholder = arg.Name
+		ti.CharLimit = 256
+		ti.Width = 50
+		ti.SetValue(arg.Default)
+		if i == 0 {
+			ti.Focus()
+		}
+		inputs[i] = ti
+	}
+
+	return Model{
+		state:    StateForm,
+		tool:     t,
+		inputs:   inputs,
+		viewport: viewport.New(w, h-4),
+		width:    w,
+		height:   h,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	// Sans argument à saisir, le script est lancé immédiatement
+	if len(m.inputs) == 0 {
+		return func() tea.Msg { return startMsg{} }
+	}
+	return textinput.Blink
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.viewport.Width = msg.Width
+		m.viewport.Height = msg.Height - 4
+
+	case startMsg:
+		return m.run()
+
+	// Fin d'exécution : retour direct au menu en mode exec, affichage de la sortie en mode capture
+	case scriptDoneMsg:
+		if msg.run != m.runID {
+			return m, nil
+		}
+		m.stopRun()
+		m.exitCode, m.err = exitStatus(msg.err)
+		if m.tool.Mode != ModeCapture {
+			return m, tools.BackWithStatus(m.statusLine())
+		}
+		m.viewport.SetContent(msg.output)
+		m.viewport.GotoTop()
+		m.state = StateOutput
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.stopRun()
+			return m, tools.Back
+		}
+
+		switch m.state {
+		// Formulaire de saisie des arguments
+		case StateForm:
+			switch msg.String() {
+			case "esc":
+				return m, tools.Back
+			case "tab", "down":
+				m.focusInput(m.focus + 1)
+				return m, nil
+			case "shift+tab", "up":
+				m.focusInput(m.focus - 1)
+				return m, nil
+			case "enter":
+				if m.focus < len(m.inputs)-1 {
+					m.focusInput(m.focus + 1)
+					return m, nil
+				}
+				return m.run()
+			}
+			if len(m.inputs) > 0 {
+				m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+			}
+			return m, cmd
+
+		// Exécution en cours : esc interrompt le script
+		case StateRunning:
+			if msg.String() == "esc" {
+				m.stopRun()
+				return m, tools.BackWithStatus(fmt.Sprintf("%s : interrompu", m.tool.Name))
+			}
+			return m, nil
+
+		// Panneau de sortie capturée
+		case StateOutput:
+			switch msg.String() {
+			case "q", "esc":
+				return m, tools.BackWithStatus(m.statusLine())
+			}
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	title := titleStyle.Render(m.tool.Name)
+
+	switch m.state {
+	// Vue 1 : Formulaire des arguments
+	case StateForm:
+		var b strings.Builder
+		for i, arg := range m.tool.Args {
+			label := arg.Prompt
+			if label == "" {
+				label = arg.Name
+			}
+			b.WriteString(fmt.Sprintf("  %s\n  %s\n\n", labelStyle.Render(label), m.inputs[i].View()))
+		}
+		footer := helpStyle.Render("tab/↑/↓: champ • enter: valider • esc: retour")
+		return fmt.Sprintf("\n  %s\n  %s\n\n%s  %s", title, cmdStyle.Render(m.tool.Description), b.String(), footer)
+
+	// Vue 2 : Exécution en cours
+	case StateRunning:
+		return fmt.Sprintf("\n  %s\n\n  Exécution de %s...\n\n  %s", title, cmdStyle.Render(m.command), helpStyle.Render("esc: interrompre"))
+
+	// Vue 3 : Sortie capturée avec le code de sortie
+	case StateOutput:
+		header := fmt.Sprintf("%s  %s  %s", title, cmdStyle.Render(m.command), m.statusStyle().Render(m.statusLine()))
+		footer := helpStyle.Render("\n↑/↓: défiler • q/esc: retour")
+		return fmt.Sprintf("%s\n%s%s", header, m.viewport.View(), footer)
+	}
+
+	return ""
+}
+
+// Déplace le focus du formulaire (avec rebouclage)
+func (m *Model) focusInput(i int) {
+	if len(m.inputs) == 0 {
+		return
+	}
+	m.inputs[m.focus].Blur()
+	m.focus = (i + len(m.inputs)) % len(m.inputs)
+	m.inputs[m.focus].Focus()
+}
+
+// Construit la commande à partir des valeurs saisies et la lance selon le mode choisi
+func (m Model) run() (Model, tea.Cmd) {
+	values := make(map[string]string, len(m.inputs))
+	for i, arg := range m.tool.Args {
+		values[arg.Name] = m.inputs[i].Value()
+	}
+	m.command = expandCommand(m.tool.Command, values)
+	m.state = StateRunning
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.runID++
+	m.cancel = cancel
+	run := m.runID
+
+	// Exécution via sh pour garantir l'échappement des arguments quel que soit le shell de l'utilisateur
+	c := exec.CommandContext(ctx, "sh", "-c", m.command)
+	c.Dir = m.tool.Dir
+	c.WaitDelay = runWaitDelay
+
+	if m.tool.Mode == ModeCapture {
+		// Groupe de processus propre : l'annulation atteint aussi les commandes lancées par le script
+		c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		c.Cancel = func() error {
+			return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+		}
+		return m, func() tea.Msg {
+			out, err := c.CombinedOutput()
+			return scriptDoneMsg{run: run, output: string(out), err: err}
+		}
+	}
+
+	return m, tea.ExecProcess(c, func(err error) tea.Msg {
+		return scriptDoneMsg{run: run, err: err}
+	})
+}
+
+// Annule l'exécution en cours ; son résultat éventuel sera ignoré
+func (m *Model) stopRun() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.runID++
+}
+
+// Message de statut affiché au retour dans le menu
+func (m Model) statusLine() string {
+	if m.err != nil {
+		return fmt.Sprintf("%s : échec (%v)", m.tool.Name, m.err)
+	}
+	return fmt.Sprintf("%s : code de sortie %d", m.tool.Name, m.exitCode)
+}
+
+func (m Model) statusStyle() lipgloss.Style {
+	if m.err != nil || m.exitCode != 0 {
+		return errorStyle
+	}
+	return okStyle
+}
+
+// Remplace les {{nom}} de la commande par les valeurs échappées pour le shell
+// Le remplacement se fait en une passe sur le modèle d'origine : une valeur contenant {{autre}} n'est pas réinterprétée
+func expandCommand(command string, values map[string]string) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		pairs = append(pairs, "{{"+name+"}}", shellQuote(values[name]))
+	}
+	return strings.NewReplacer(pairs...).Replace(command)
+}
+
+// Échappe une valeur entre apostrophes pour sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Extrait le code de sortie du processus ; err n'est renvoyée que si le lancement lui-même a échoué
+func exitStatus(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	return -1, err
+}
@@ -0,0 +1,59 @@
+package script
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExpandCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		values  map[string]string
+		want    string
+	}{
+		{"simple", "ping -c 4 {{hote}}", map[string]string{"hote": "1.1.1.1"}, "ping -c 4 '1.1.1.1'"},
+		{"apostrophe", "echo {{msg}}", map[string]string{"msg": "l'hôte"}, `echo 'l'\''hôte'`},
+		{"injection", "echo {{a}}", map[string]string{"a": "$(rm -rf ~); `id`"}, "echo '$(rm -rf ~); `id`'"},
+		{"répété", "{{x}} {{x}}", map[string]string{"x": "v"}, "'v' 'v'"},
+		{"inconnu", "echo {{y}}", map[string]string{"x": "v"}, "echo {{y}}"},
+		// Une valeur contenant un autre {{nom}} n'est pas remplacée une seconde fois
+		{"pas de réexpansion", "cp {{src}} {{dst}}", map[string]string{"src": "{{dst}}", "dst": "'; id; '"},
+			`cp '{{dst}}' ''\''; id; '\'''`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Plusieurs passes : le résultat ne doit pas dépendre de l'ordre de parcours de la map
+			for i := 0; i < 20; i++ {
+				if got := expandCommand(tt.command, tt.values); got != tt.want {
+					t.Fatalf("expandCommand(%q) = %q, attendu %q", tt.command, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// Une exécution interrompue tue le script et ses descendants, et son résultat est ignoré
+func TestRunCancel(t *testing.T) {
+	m := New(ToolConfig{Name: "long", Command: "sleep 30 & sleep 30; wait", Mode: ModeCapture}, 80, 24)
+	m, cmd := m.run()
+
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+
+	var msg tea.Msg
+	select {
+	case msg = <-done:
+	case <-time.After(runWaitDelay + 5*time.Second):
+		t.Fatal("le script n'a pas été interrompu")
+	}
+	next, _ = m.Update(msg)
+	if got := next.(Model); got.state != StateRunning || got.viewport.TotalLineCount() > 1 {
+		t.Errorf("résultat d'une exécution annulée pris en compte : état %d", got.state)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/Quirky1869/cyberTools/tools"
 	"github.com/charmbracelet/bubbles/filepicker"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/table"
@@ -14,8 +16,18 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// Message de retour pour signaler au menu principal de reprendre la main
-type BackMsg struct{}
+// Enregistrement de l'outil dans le menu principal
+func init() {
+	tools.Register(tools.Tool{
+		Name:        "SqlTUI",
+		Description: "Explorateur SQL",
+		Category:    "BDD",
+		New:         func(w, h int) tea.Model { return New(w, h) },
+		Command:     "sqltui",
+		Usage:       "<base.db>",
+		Open:        func(w, h int, arg string) (tea.Model, error) { return Open(w, h, arg) },
+	})
+}
 
 // États de la machine à états interne
 type SessionState int
@@ -110,6 +122,23 @@ func New(w, h int) Model {
 	}
 }
 
+// Ouvre directement la base donnée sur la liste des tables
+// Le fichier doit exister : SQLite créerait sinon une base vide
+func Open(w, h int, path string) (Model, error) {
+	m := New(w, h)
+	if _, err := os.Stat(path); err != nil {
+		return m, err
+	}
+	if err := m.openDB(path); err != nil {
+		return m, err
+	}
+	m.dbPath = path
+	m.filePicker.CurrentDirectory = filepath.Dir(path)
+	m.state = StateBrowser
+	m.loadTables()
+	return m, nil
+}
+
 func (m Model) Init() tea.Cmd {
 	return m.filePicker.Init()
 }
@@ -132,7 +161,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.state == StateSelectFile {
 			switch msg.String() {
 			case "q":
-				return m, func() tea.Msg { return BackMsg{} }
+				return m, tools.Back
 			case "h":
 				m.filePicker.ShowHidden = !m.filePicker.ShowHidden
 				return m, m.filePicker.Init()
@@ -143,7 +172,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.db != nil {
 				m.db.Close()
 			}
-			return m, func() tea.Msg { return BackMsg{} }
+			return m, tools.Back
 		}
 	}
 
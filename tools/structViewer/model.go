@@ -3,17 +3,29 @@ package structviewer
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/Quirky1869/cyberTools/tools"
 	"github.com/charmbracelet/bubbles/filepicker"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"gopkg.in/yaml.v3"
 )
 
-// Signal de retour au menu principal
-type BackMsg struct{}
+// Enregistrement de l'outil dans le menu principal
+func init() {
+	tools.Register(tools.Tool{
+		Name:        "structViewer",
+		Description: "Lecteur YAML/JSON arborescent",
+		Category:    "Utilitaire",
+		New:         func(w, h int) tea.Model { return New(w, h) },
+		Command:     "struct",
+		Usage:       "<fichier.yaml|json>",
+		Open:        func(w, h int, arg string) (tea.Model, error) { return Open(w, h, arg) },
+	})
+}
 
 // États de la machine à états (sélection ou navigation)
 type SessionState int
@@ -84,6 +96,17 @@ func New(w, h int) Model {
 	}
 }
 
+// Ouvre directement le fichier donné en vue arborescente
+func Open(w, h int, path string) (Model, error) {
+	m := New(w, h)
+	if err := m.loadAndParseYAML(path); err != nil {
+		return m, err
+	}
+	m.filePicker.CurrentDirectory = filepath.Dir(path)
+	m.state = StateTree
+	return m, nil
+}
+
 func (m Model) Init() tea.Cmd {
 	return m.filePicker.Init()
 }
@@ -100,14 +123,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
-			return m, func() tea.Msg { return BackMsg{} }
+			return m, tools.Back
 		}
 
 		// Gestion de la vue sélection de fichier
 		if m.state == StateSelectFile {
 			switch msg.String() {
 			case "q", "esc":
-				return m, func() tea.Msg { return BackMsg{} }
+				return m, tools.Back
 			case "h":
 				m.filePicker.ShowHidden = !m.filePicker.ShowHidden
 				return m, m.filePicker.Init()
@@ -4,10 +4,6 @@ import (
 	"strings"
 
 	"github.com/Quirky1869/cyberTools/tools"
-	aeddsa "github.com/Quirky1869/cyberTools/tools/aed"
-	"github.com/Quirky1869/cyberTools/tools/logv"
-	"github.com/Quirky1869/cyberTools/tools/sqltui"
-	structviewer "github.com/Quirky1869/cyberTools/tools/structViewer"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -36,6 +32,7 @@ type Model struct {
 	width           int
 	height          int
 	currentTool     tea.Model
+	status          string
 }
 
 // Initialisation du modèle avec chargement du thème et des catégories
@@ -55,30 +52,33 @@ func NewModel() Model {
 	}
 }
 
+// Démarre l'application directement dans un outil déjà initialisé (sous-commande en ligne de commande)
+func NewModelWithTool(t tea.Model) Model {
+	m := NewModel()
+	m.currentTool = t
+	return m
+}
+
 func (m Model) Init() tea.Cmd {
+	if m.currentTool != nil {
+		return m.currentTool.Init()
+	}
 	return nil
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Gestion de l'outil actif : on délègue les événements à l'outil ou on revient au menu (BackMsg)
 	if m.currentTool != nil {
-		if _, ok := msg.(logv.BackMsg); ok {
-			m.currentTool = nil
-			return m, tea.ClearScreen
-		}
-
-		if _, ok := msg.(sqltui.BackMsg); ok {
-			m.currentTool = nil
-			return m, tea.ClearScreen
-		}
-
-		if _, ok := msg.(structviewer.BackMsg); ok {
-			m.currentTool = nil
-			return m, tea.ClearScreen
+		// Les dimensions sont mémorisées pour que le menu soit prêt au retour
+		if size, ok := msg.(tea.WindowSizeMsg); ok {
+			m.width = size.Width
+			m.height = size.Height
+			m.help.Width = size.Width
 		}
 
-		if _, ok := msg.(aeddsa.BackMsg); ok {
+		if back, ok := msg.(tools.BackMsg); ok {
 			m.currentTool = nil
+			m.status = back.Status
 			return m, tea.ClearScreen
 		}
 
@@ -96,6 +96,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.help.Width = msg.Width
 
 	case tea.KeyMsg:
+		// Le statut du dernier outil reste affiché jusqu'à la prochaine action
+		m.status = ""
+
 		switch {
 		// Commandes système (Quitter, Aide)
 		case key.Matches(msg, m.keys.Quit):
@@ -148,28 +151,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.focus == FocusTools {
 				tool := m.categories[m.activeCatIndex].Tools[m.activeToolIndex]
 
-				if tool.Name == "LogV" {
-					lv := logv.New(m.width, m.height)
-					m.currentTool = lv
-					return m, lv.Init()
-				}
-
-				if tool.Name == "SqlTUI" {
-					st := sqltui.New(m.width, m.height)
-					m.currentTool = st
-					return m, st.Init()
-				}
-
-				if tool.Name == "structViewer" {
-					ty := structviewer.New(m.width, m.height)
-					m.currentTool = ty
-					return m, ty.Init()
-				}
-
-				if tool.Name == "AED" {
-					a := aeddsa.New(m.width, m.height)
-					m.currentTool = a
-					return m, a.Init()
+				if tool.New != nil {
+					t := tool.New(m.width, m.height)
+					m.currentTool = t
+					return m, t.Init()
 				}
 			}
 		}
@@ -248,6 +233,9 @@ func (m Model) View() string {
 	// Assemblage final de l'interface graphique
 	helpView := m.help.View(m.keys)
 	appContent := lipgloss.JoinVertical(lipgloss.Left, row, contentBox, "\n"+helpView)
+	if m.status != "" {
+		appContent = lipgloss.JoinVertical(lipgloss.Left, appContent, "\n"+m.styles.Status.Render(m.status))
+	}
 
 	centeredAppContent := lipgloss.PlaceHorizontal(
 		m.width,
@@ -56,6 +56,7 @@ type Styles struct {
 	Tool         lipgloss.Style
 	ToolName     lipgloss.Style
 	ToolDesc     lipgloss.Style
+	Status       lipgloss.Style
 	Help         help.Styles
 	Palette      ThemePalette
 }
@@ -106,6 +107,8 @@ func MakeStyles(t ThemePalette) Styles {
 
 		ToolDesc: lipgloss.NewStyle().Foreground(lipgloss.Color(t.Gray)),
 
+		Status: lipgloss.NewStyle().Foreground(lipgloss.Color(t.Secondary)).Italic(true),
+
 		Help: help.Styles{
 			ShortKey:  lipgloss.NewStyle().Foreground(lipgloss.Color(t.Septenary)),
 			FullKey:   lipgloss.NewStyle().Foreground(lipgloss.Color(t.Septenary)),